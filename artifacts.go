@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+)
+
+// File names used inside the keys directory written by `setup`
+const (
+	CIRCUIT_FILE       = "circuit.r1cs"
+	PROVING_KEY_FILE   = "proving.key"
+	VERIFYING_KEY_FILE = "verifying.key"
+)
+
+// IdentityFile is the JSON artifact holding a member's secret
+type IdentityFile struct {
+	Secret     string `json:"secret"`
+	Commitment string `json:"commitment"`
+	Mnemonic   string `json:"mnemonic,omitempty"`
+}
+
+// newIdentityFile returns the identity file of `id`, `mnemonic` is empty
+// for identities that weren't derived from a mnemonic
+func newIdentityFile(id *identity.Identity, mnemonic string) IdentityFile {
+	return IdentityFile{
+		Secret:     id.Secret().String(),
+		Commitment: id.Commitment().String(),
+		Mnemonic:   mnemonic,
+	}
+}
+
+// CommitmentFile is the public part of an identity that can be shared with group admins
+type CommitmentFile struct {
+	Commitment string `json:"commitment"`
+}
+
+// GroupFile is the JSON artifact holding the members of a group
+type GroupFile struct {
+	Members []string `json:"members"`
+}
+
+// RootFile is the JSON artifact holding the root of a group
+type RootFile struct {
+	Root  string `json:"root"`
+	Depth int    `json:"depth"`
+	Size  int    `json:"size"`
+}
+
+// MerkleProofFile is the JSON artifact holding a merkle proof of a group member
+type MerkleProofFile struct {
+	Index    int      `json:"index"`
+	Leaf     string   `json:"leaf"`
+	Root     string   `json:"root"`
+	Path     []int    `json:"path"`
+	Siblings []string `json:"siblings"`
+}
+
+// ProofFile is the JSON artifact holding a semaphore proof and its groth16 proof
+type ProofFile struct {
+	MerkleRoot string            `json:"merkleRoot"`
+	Nullifier  string            `json:"nullifier"`
+	Message    string            `json:"message"`
+	Scope      string            `json:"scope"`
+	Domain     *semaphore.Domain `json:"domain,omitempty"` // the domain the proof is bound to, if any
+	Proof      string            `json:"proof"`            // hex encoded groth16 proof
+
+	NullifierMode  string `json:"nullifierMode,omitempty"`  // per_epoch or per_app, per scope if empty
+	NullifierInput string `json:"nullifierInput,omitempty"` // the epoch or the app salt of the nullifier
+	Timestamp      string `json:"timestamp,omitempty"`      // the unix time of a timestamped proof
+}
+
+// readJSON decodes the JSON file at `path` into `v`
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return nil
+}
+
+// writeJSON encodes `v` into the file at `path`, or into `stdout` if `path` is empty
+func writeJSON(path string, stdout io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// parseBigInt parses a decimal (or 0x prefixed hexadecimal) big integer
+func parseBigInt(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return v, nil
+}
+
+// parseBigInts parses a list of big integers
+func parseBigInts(ss []string) ([]*big.Int, error) {
+	res := []*big.Int{}
+	for _, s := range ss {
+		v, err := parseBigInt(s)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// formatBigInts formats a list of big integers as decimal strings
+func formatBigInts(vs []*big.Int) []string {
+	res := []string{}
+	for _, v := range vs {
+		res = append(res, v.String())
+	}
+	return res
+}
+
+// loadIdentity reads the identity file at `path` and returns its secret
+func loadIdentity(path string) (*big.Int, error) {
+	var f IdentityFile
+	if err := readJSON(path, &f); err != nil {
+		return nil, err
+	}
+	secret, err := parseBigInt(f.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := identity.FromSecret(secret); err != nil {
+		return nil, fmt.Errorf("invalid identity file %s: %w", path, err)
+	}
+	return secret, nil
+}
+
+// parseNullifierMode parses the name of a nullifier mode, per scope if empty
+func parseNullifierMode(s string) (semaphore.NullifierMode, error) {
+	for _, mode := range []semaphore.NullifierMode{
+		semaphore.NULLIFIER_PER_SCOPE,
+		semaphore.NULLIFIER_PER_EPOCH,
+		semaphore.NULLIFIER_PER_APP,
+	} {
+		if s == mode.String() {
+			return mode, nil
+		}
+	}
+	if s == "" {
+		return semaphore.NULLIFIER_PER_SCOPE, nil
+	}
+	return 0, fmt.Errorf("unknown nullifier mode %q (per_scope, per_epoch or per_app)", s)
+}
+
+// loadDomain reads the domain file at `path`, nil if `path` is empty
+func loadDomain(path string) (*semaphore.Domain, error) {
+	if path == "" {
+		return nil, nil
+	}
+	var domain semaphore.Domain
+	if err := readJSON(path, &domain); err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// loadGroup reads the group file at `path` and rebuilds its lean IMT
+func loadGroup(path string) (*leanIMT.LeanIMT, error) {
+	var f GroupFile
+	if err := readJSON(path, &f); err != nil {
+		return nil, err
+	}
+	members, err := parseBigInts(f.Members)
+	if err != nil {
+		return nil, err
+	}
+	return leanIMT.NewLeanIMT(semaphore.MimcHash, members)
+}
+
+// saveGroup writes the members of the group into the group file at `path`
+func saveGroup(path string, imt *leanIMT.LeanIMT) error {
+	members := []*big.Int{}
+	if imt.Size() != 0 {
+		members = imt.Nodes[0]
+	}
+	return writeJSON(path, nil, GroupFile{Members: formatBigInts(members)})
+}
+
+// writeKeys stores the constraint system and groth16 keys into `dir`
+func writeKeys(dir string, ccs constraint.ConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	objs := map[string]io.WriterTo{
+		CIRCUIT_FILE:       ccs,
+		PROVING_KEY_FILE:   pk,
+		VERIFYING_KEY_FILE: vk,
+	}
+	for name, obj := range objs {
+		if err := writeObject(filepath.Join(dir, name), obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readProverKeys loads the constraint system and the proving key from `dir`
+func readProverKeys(dir string) (constraint.ConstraintSystem, groth16.ProvingKey, error) {
+	ccs := groth16.NewCS(ecc.BN254)
+	if err := readObject(filepath.Join(dir, CIRCUIT_FILE), ccs); err != nil {
+		return nil, nil, err
+	}
+	pk := groth16.NewProvingKey(ecc.BN254)
+	if err := readObject(filepath.Join(dir, PROVING_KEY_FILE), pk); err != nil {
+		return nil, nil, err
+	}
+	return ccs, pk, nil
+}
+
+// readVerifyingKey loads the verifying key from `dir`
+func readVerifyingKey(dir string) (groth16.VerifyingKey, error) {
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	if err := readObject(filepath.Join(dir, VERIFYING_KEY_FILE), vk); err != nil {
+		return nil, err
+	}
+	return vk, nil
+}
+
+// writeObject serializes a gnark object into the file at `path`
+func writeObject(path string, obj io.WriterTo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := obj.WriteTo(f); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return f.Close()
+}
+
+// readObject deserializes a gnark object from the file at `path`
+func readObject(path string, obj io.ReaderFrom) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := obj.ReadFrom(f); err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return nil
+}
+
+// encodeProof converts a semaphore proof and its groth16 proof into a proof file
+func encodeProof(proof *groth16_bn254.Proof, sProof semaphore.SemaphoreProof) (ProofFile, error) {
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return ProofFile{}, err
+	}
+	f := ProofFile{
+		MerkleRoot: sProof.MerkleRoot.String(),
+		Nullifier:  sProof.Nullifier.String(),
+		Message:    sProof.Message.String(),
+		Scope:      sProof.Scope.String(),
+		Domain:     sProof.Domain,
+		Proof:      hex.EncodeToString(buf.Bytes()),
+	}
+	if sProof.NullifierMode != semaphore.NULLIFIER_PER_SCOPE {
+		f.NullifierMode = sProof.NullifierMode.String()
+		f.NullifierInput = sProof.NullifierInput.String()
+	}
+	if sProof.Timestamp != nil {
+		f.Timestamp = sProof.Timestamp.String()
+	}
+	return f, nil
+}
+
+// decodeProof converts a proof file into a semaphore proof and its groth16 proof
+func decodeProof(f ProofFile) (*groth16_bn254.Proof, semaphore.SemaphoreProof, error) {
+	var sProof semaphore.SemaphoreProof
+	vals, err := parseBigInts([]string{f.MerkleRoot, f.Nullifier, f.Message, f.Scope})
+	if err != nil {
+		return nil, sProof, err
+	}
+	sProof.MerkleRoot, sProof.Nullifier, sProof.Message, sProof.Scope = vals[0], vals[1], vals[2], vals[3]
+	sProof.Domain = f.Domain
+	if sProof.NullifierMode, err = parseNullifierMode(f.NullifierMode); err != nil {
+		return nil, sProof, err
+	}
+	if sProof.NullifierMode != semaphore.NULLIFIER_PER_SCOPE {
+		if sProof.NullifierInput, err = parseBigInt(f.NullifierInput); err != nil {
+			return nil, sProof, err
+		}
+	}
+	if f.Timestamp != "" {
+		if sProof.Timestamp, err = parseBigInt(f.Timestamp); err != nil {
+			return nil, sProof, err
+		}
+	}
+
+	raw, err := hex.DecodeString(f.Proof)
+	if err != nil {
+		return nil, sProof, fmt.Errorf("invalid proof encoding: %v", err)
+	}
+	proof := new(groth16_bn254.Proof)
+	if _, err := proof.ReadFrom(bytes.NewReader(raw)); err != nil {
+		return nil, sProof, fmt.Errorf("invalid proof: %v", err)
+	}
+	return proof, sProof, nil
+}
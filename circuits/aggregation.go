@@ -0,0 +1,71 @@
+package circuits
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// Types of the emulated BN254 Groth16 verifier
+type (
+	InnerProof        = stdgroth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]
+	InnerVerifyingKey = stdgroth16.VerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]
+)
+
+// INNER_PUBLIC_INPUTS is the number of public inputs of the aggregated proofs, those of the
+// Semaphore circuit. The variants with more public inputs can't be aggregated
+const INNER_PUBLIC_INPUTS = 5
+
+// Aggregation verifies a batch of Semaphore Groth16 proofs, the public inputs
+// of every proof are public inputs of the aggregation. The BN254 proofs are
+// verified with emulated arithmetic, so that the aggregated proof is a BN254
+// Groth16 proof too, verifiable by the same precompiles
+type Aggregation struct {
+	// VerifyingKey is the verifying key of the Semaphore circuit, a constant of the circuit
+	VerifyingKey InnerVerifyingKey `gnark:"-"`
+	Proofs       []InnerProof
+	Messages     []frontend.Variable `gnark:",public"`
+	Scopes       []frontend.Variable `gnark:",public"`
+	MerkleRoots  []frontend.Variable `gnark:",public"`
+	Nullifiers   []frontend.Variable `gnark:",public"`
+}
+
+func (circuit *Aggregation) Define(api frontend.API) error {
+	n := len(circuit.Proofs)
+	if len(circuit.Messages) != n || len(circuit.Scopes) != n || len(circuit.MerkleRoots) != n || len(circuit.Nullifiers) != n {
+		return fmt.Errorf("the number of public inputs doesn't match the number of proofs")
+	}
+	verifier, err := stdgroth16.NewVerifier[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](api)
+	if err != nil {
+		return err
+	}
+	fr, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return err
+	}
+	// The scalar field of BN254 is the native field, so the public
+	// inputs are converted to emulated elements from their bits
+	toEmulated := func(v frontend.Variable) emulated.Element[sw_bn254.ScalarField] {
+		return *fr.FromBits(api.ToBinary(v)...)
+	}
+
+	for i := 0; i < n; i++ {
+		// Same order as the public inputs of the Semaphore circuit
+		witness := stdgroth16.Witness[sw_bn254.ScalarField]{
+			Public: []emulated.Element[sw_bn254.ScalarField]{
+				toEmulated(circuit.Messages[i]),
+				toEmulated(circuit.Scopes[i]),
+				toEmulated(api.Mul(circuit.Messages[i], circuit.Messages[i])),
+				toEmulated(circuit.MerkleRoots[i]),
+				toEmulated(circuit.Nullifiers[i]),
+			},
+		}
+		if err := verifier.AssertProof(circuit.VerifyingKey, circuit.Proofs[i], witness); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// INDEXED_DEPTH is the depth of the indexed merkle trees, such as the blocklists
+const INDEXED_DEPTH = 16
+
+// IndexedMerkleNonMembership proves that Value isn't in the indexed merkle tree
+// of root Root: the low leaf (LowValue, LowNextIndex, LowNextValue) is in the tree
+// and LowValue < Value < LowNextValue, or LowNextValue is 0 (the end of the list)
+type IndexedMerkleNonMembership struct {
+	Value        frontend.Variable
+	LowValue     frontend.Variable
+	LowNextIndex frontend.Variable
+	LowNextValue frontend.Variable
+	Indices      [INDEXED_DEPTH]frontend.Variable
+	Siblings     [INDEXED_DEPTH]frontend.Variable
+	Root         frontend.Variable `gnark:",public"`
+}
+
+func (circuit *IndexedMerkleNonMembership) Define(api frontend.API) error {
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+
+	// Merkle proof of the low leaf
+	hFunc.Reset()
+	hFunc.Write(circuit.LowValue, circuit.LowNextIndex, circuit.LowNextValue)
+	node := hFunc.Sum()
+	for i := 0; i < INDEXED_DEPTH; i++ {
+		api.AssertIsBoolean(circuit.Indices[i])
+		leftChild := api.Select(circuit.Indices[i], circuit.Siblings[i], node)
+		rightChild := api.Select(circuit.Indices[i], node, circuit.Siblings[i])
+		hFunc.Reset()
+		hFunc.Write(leftChild, rightChild)
+		node = hFunc.Sum()
+	}
+	api.AssertIsEqual(circuit.Root, node)
+
+	// LowValue < Value
+	api.AssertIsEqual(api.Cmp(circuit.LowValue, circuit.Value), -1)
+	// Value < LowNextValue, unless the low leaf is the last one
+	isLast := api.IsZero(circuit.LowNextValue)
+	isLower := api.IsZero(api.Add(api.Cmp(circuit.Value, circuit.LowNextValue), 1))
+	api.AssertIsEqual(api.Or(isLast, isLower), 1)
+	return nil
+}
@@ -0,0 +1,45 @@
+package circuits
+
+import (
+	"math/big"
+
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// KeyClaim proves that the Baby Jubjub public key (PublicKeyX, PublicKeyY) and the identity
+// commitment share their secret: Commitment = MiMC(Secret) and PublicKey = Secret·B8.
+// The public key is in the coordinates of circomlib and iden3, with a = 168700
+type KeyClaim struct {
+	Secret     frontend.Variable
+	Commitment frontend.Variable `gnark:",public"`
+	PublicKeyX frontend.Variable `gnark:",public"`
+	PublicKeyY frontend.Variable `gnark:",public"`
+}
+
+// reducedX maps the x coordinate of circomlib, a = 168700, to the one of gnark, a = -1:
+// x' = sqrt(-168700)·x, the y coordinates are the same
+var reducedX, _ = new(big.Int).SetString("15527681003928902128179717624703512672403908117992798440346960750464748824729", 10)
+
+func (circuit *KeyClaim) Define(api frontend.API) error {
+	assertSecret(api, circuit.Secret)
+
+	m, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	m.Write(circuit.Secret)
+	api.AssertIsEqual(circuit.Commitment, m.Sum())
+
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	base := twistededwards.Point{X: curve.Params().Base[0], Y: curve.Params().Base[1]}
+	pk := curve.ScalarMul(base, circuit.Secret)
+	api.AssertIsEqual(pk.X, api.Mul(circuit.PublicKeyX, reducedX))
+	api.AssertIsEqual(pk.Y, circuit.PublicKeyY)
+	return nil
+}
@@ -0,0 +1,28 @@
+package circuits
+
+// NullifierMode is the derivation of the nullifier
+type NullifierMode int
+
+const (
+	// NULLIFIER_PER_SCOPE is MiMC(Scope, Secret), the nullifier of the Semaphore circuit:
+	// a member signals once per scope
+	NULLIFIER_PER_SCOPE NullifierMode = iota
+	// NULLIFIER_PER_EPOCH is MiMC(Scope, Epoch, Secret): a member signals once per scope
+	// and epoch, e.g. once a day
+	NULLIFIER_PER_EPOCH
+	// NULLIFIER_PER_APP is MiMC(AppSalt, Scope, Secret): a member signals once per scope,
+	// and its nullifiers in different apps using the same scopes are unlinkable
+	NULLIFIER_PER_APP
+)
+
+func (mode NullifierMode) String() string {
+	switch mode {
+	case NULLIFIER_PER_SCOPE:
+		return "per_scope"
+	case NULLIFIER_PER_EPOCH:
+		return "per_epoch"
+	case NULLIFIER_PER_APP:
+		return "per_app"
+	}
+	return "unknown"
+}
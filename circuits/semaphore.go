@@ -20,38 +20,59 @@ type Semaphore struct {
 }
 
 func (circuit *Semaphore) Define(api frontend.API) error {
-	// The secret scalar must be in the prime subgroup order l + 1
-	l := new(big.Int)
-	l.SetString("2736030358979909402780800718157159386076813972158567259200215660948447373040", 10)
-	api.AssertIsLessOrEqual(circuit.Secret, l)
+	_, merkleRoot, err := circuit.define(api, nil, nil)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(circuit.MerkleRoot, merkleRoot)
+	return nil
+}
+
+// define adds the constraints of the circuit but the check of the merkle root, and returns
+// the identity commitment and the merkle root, so that the variants of the circuit can
+// add constraints on them. The leaf of the member is `leafOf(idc)`, or idc if `leafOf` is nil.
+// The nullifier is MiMC(nullifierInputs..., Secret), or MiMC(Scope, Secret) if `nullifierInputs` is nil
+func (circuit *Semaphore) define(
+	api frontend.API,
+	leafOf func(idc frontend.Variable) (frontend.Variable, error),
+	nullifierInputs []frontend.Variable,
+) (idc, merkleRoot frontend.Variable, err error) {
+	assertSecret(api, circuit.Secret)
 
 	// Calculate Identity Commitment
 	// Calculate public key from the secret
 	// TODO: Use Public Key
 	m, err := mimc.NewMiMC(api)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	m.Reset()
 	m.Write(circuit.Secret)
-	idc := m.Sum()
+	idc = m.Sum()
+	leaf := idc
+	if leafOf != nil {
+		if leaf, err = leafOf(idc); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// Calculate Merkle Root
-	merkleRoot := BinaryMerkleRoot{
-		Leaf:     idc,
+	binaryMerkleRoot := BinaryMerkleRoot{
+		Leaf:     leaf,
 		Depth:    circuit.MerkleProofLength,
 		Indices:  circuit.MerkleProofIndices,
 		Siblings: circuit.MerkleProofSiblings,
 	}
-	if err := merkleRoot.Define(api); err != nil {
-		return err
+	if err := binaryMerkleRoot.Define(api); err != nil {
+		return nil, nil, err
 	}
-	calculatedMerkleRoot := merkleRoot.Out
-	api.AssertIsEqual(circuit.MerkleRoot, calculatedMerkleRoot)
 
 	// Calculate Nullifier
+	if nullifierInputs == nil {
+		nullifierInputs = []frontend.Variable{circuit.Scope}
+	}
 	m.Reset()
-	m.Write(circuit.Scope)
+	m.Write(nullifierInputs...)
 	m.Write(circuit.Secret)
 	calculatedNullifier := m.Sum()
 	api.AssertIsEqual(circuit.Nullifier, calculatedNullifier)
@@ -60,5 +81,12 @@ func (circuit *Semaphore) Define(api frontend.API) error {
 	calculatedDummySquare := api.Mul(circuit.Message, circuit.Message)
 	api.AssertIsEqual(circuit.DummySquare, calculatedDummySquare)
 
-	return nil
+	return idc, binaryMerkleRoot.Out, nil
+}
+
+// assertSecret checks that the secret scalar is in the prime subgroup order l + 1
+func assertSecret(api frontend.API, secret frontend.Variable) {
+	l := new(big.Int)
+	l.SetString("2736030358979909402780800718157159386076813972158567259200215660948447373040", 10)
+	api.AssertIsLessOrEqual(secret, l)
 }
@@ -0,0 +1,50 @@
+package circuits
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// SemaphoreAnyOf is the Semaphore circuit for several groups: it proves the membership
+// in one of the groups of root MerkleRoots without revealing which one. The number of
+// roots is set when the circuit is compiled
+type SemaphoreAnyOf struct {
+	Secret              frontend.Variable
+	MerkleProofLength   frontend.Variable
+	MerkleProofIndices  [MAX_DEPTH]frontend.Variable
+	MerkleProofSiblings [MAX_DEPTH]frontend.Variable
+	Message             frontend.Variable   `gnark:",public"`
+	Scope               frontend.Variable   `gnark:",public"`
+	DummySquare         frontend.Variable   `gnark:",public"`
+	MerkleRoots         []frontend.Variable `gnark:",public"`
+	Nullifier           frontend.Variable   `gnark:",public"`
+}
+
+func (circuit *SemaphoreAnyOf) Define(api frontend.API) error {
+	if len(circuit.MerkleRoots) == 0 {
+		return fmt.Errorf("missing merkle roots")
+	}
+	semaphore := Semaphore{
+		Secret:              circuit.Secret,
+		MerkleProofLength:   circuit.MerkleProofLength,
+		MerkleProofIndices:  circuit.MerkleProofIndices,
+		MerkleProofSiblings: circuit.MerkleProofSiblings,
+		Message:             circuit.Message,
+		Scope:               circuit.Scope,
+		DummySquare:         circuit.DummySquare,
+		Nullifier:           circuit.Nullifier,
+	}
+	_, merkleRoot, err := semaphore.define(api, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// The merkle root is one of the roots if the product of the differences is zero
+	product := frontend.Variable(1)
+	for _, root := range circuit.MerkleRoots {
+		product = api.Mul(product, api.Sub(merkleRoot, root))
+	}
+	api.AssertIsEqual(product, 0)
+	return nil
+}
@@ -0,0 +1,55 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// SemaphoreAttribute is the Semaphore circuit for groups whose leaves commit to an attribute
+// of the member, such as an age or a role: the leaf is MiMC(identity commitment, Attribute).
+// It also proves that the hidden attribute lies between the public AttributeMin and
+// AttributeMax, both included, so that an attribute equal to a public value has Min = Max
+type SemaphoreAttribute struct {
+	Secret              frontend.Variable
+	Attribute           frontend.Variable
+	MerkleProofLength   frontend.Variable
+	MerkleProofIndices  [MAX_DEPTH]frontend.Variable
+	MerkleProofSiblings [MAX_DEPTH]frontend.Variable
+	Message             frontend.Variable `gnark:",public"`
+	Scope               frontend.Variable `gnark:",public"`
+	DummySquare         frontend.Variable `gnark:",public"`
+	MerkleRoot          frontend.Variable `gnark:",public"`
+	Nullifier           frontend.Variable `gnark:",public"`
+	AttributeMin        frontend.Variable `gnark:",public"`
+	AttributeMax        frontend.Variable `gnark:",public"`
+}
+
+func (circuit *SemaphoreAttribute) Define(api frontend.API) error {
+	semaphore := Semaphore{
+		Secret:              circuit.Secret,
+		MerkleProofLength:   circuit.MerkleProofLength,
+		MerkleProofIndices:  circuit.MerkleProofIndices,
+		MerkleProofSiblings: circuit.MerkleProofSiblings,
+		Message:             circuit.Message,
+		Scope:               circuit.Scope,
+		DummySquare:         circuit.DummySquare,
+		Nullifier:           circuit.Nullifier,
+	}
+	_, merkleRoot, err := semaphore.define(api, func(idc frontend.Variable) (frontend.Variable, error) {
+		m, err := mimc.NewMiMC(api)
+		if err != nil {
+			return nil, err
+		}
+		m.Write(idc, circuit.Attribute)
+		return m.Sum(), nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(circuit.MerkleRoot, merkleRoot)
+
+	// AttributeMin <= Attribute <= AttributeMax
+	api.AssertIsLessOrEqual(circuit.AttributeMin, circuit.Attribute)
+	api.AssertIsLessOrEqual(circuit.Attribute, circuit.AttributeMax)
+	return nil
+}
@@ -0,0 +1,55 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// SemaphoreBlocklist is the Semaphore circuit with a blocklist: it also proves
+// that the identity commitment isn't in the indexed merkle tree of root BlocklistRoot
+type SemaphoreBlocklist struct {
+	Secret                frontend.Variable
+	MerkleProofLength     frontend.Variable
+	MerkleProofIndices    [MAX_DEPTH]frontend.Variable
+	MerkleProofSiblings   [MAX_DEPTH]frontend.Variable
+	BlocklistLowValue     frontend.Variable
+	BlocklistLowNextIndex frontend.Variable
+	BlocklistLowNextValue frontend.Variable
+	BlocklistIndices      [INDEXED_DEPTH]frontend.Variable
+	BlocklistSiblings     [INDEXED_DEPTH]frontend.Variable
+	Message               frontend.Variable `gnark:",public"`
+	Scope                 frontend.Variable `gnark:",public"`
+	DummySquare           frontend.Variable `gnark:",public"`
+	MerkleRoot            frontend.Variable `gnark:",public"`
+	Nullifier             frontend.Variable `gnark:",public"`
+	BlocklistRoot         frontend.Variable `gnark:",public"`
+}
+
+func (circuit *SemaphoreBlocklist) Define(api frontend.API) error {
+	semaphore := Semaphore{
+		Secret:              circuit.Secret,
+		MerkleProofLength:   circuit.MerkleProofLength,
+		MerkleProofIndices:  circuit.MerkleProofIndices,
+		MerkleProofSiblings: circuit.MerkleProofSiblings,
+		Message:             circuit.Message,
+		Scope:               circuit.Scope,
+		DummySquare:         circuit.DummySquare,
+		MerkleRoot:          circuit.MerkleRoot,
+		Nullifier:           circuit.Nullifier,
+	}
+	idc, merkleRoot, err := semaphore.define(api, nil, nil)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(circuit.MerkleRoot, merkleRoot)
+
+	nonMembership := IndexedMerkleNonMembership{
+		Value:        idc,
+		LowValue:     circuit.BlocklistLowValue,
+		LowNextIndex: circuit.BlocklistLowNextIndex,
+		LowNextValue: circuit.BlocklistLowNextValue,
+		Indices:      circuit.BlocklistIndices,
+		Siblings:     circuit.BlocklistSiblings,
+		Root:         circuit.BlocklistRoot,
+	}
+	return nonMembership.Define(api)
+}
@@ -0,0 +1,52 @@
+package circuits
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// SemaphoreNullifier is the Semaphore circuit with another derivation of the nullifier, set
+// when the circuit is compiled: NullifierInput is the epoch of the per epoch nullifiers,
+// or the app salt of the per app nullifiers
+type SemaphoreNullifier struct {
+	Mode                NullifierMode `gnark:"-"`
+	Secret              frontend.Variable
+	MerkleProofLength   frontend.Variable
+	MerkleProofIndices  [MAX_DEPTH]frontend.Variable
+	MerkleProofSiblings [MAX_DEPTH]frontend.Variable
+	Message             frontend.Variable `gnark:",public"`
+	Scope               frontend.Variable `gnark:",public"`
+	DummySquare         frontend.Variable `gnark:",public"`
+	MerkleRoot          frontend.Variable `gnark:",public"`
+	Nullifier           frontend.Variable `gnark:",public"`
+	NullifierInput      frontend.Variable `gnark:",public"`
+}
+
+func (circuit *SemaphoreNullifier) Define(api frontend.API) error {
+	var nullifierInputs []frontend.Variable
+	switch circuit.Mode {
+	case NULLIFIER_PER_EPOCH:
+		nullifierInputs = []frontend.Variable{circuit.Scope, circuit.NullifierInput}
+	case NULLIFIER_PER_APP:
+		nullifierInputs = []frontend.Variable{circuit.NullifierInput, circuit.Scope}
+	default:
+		return fmt.Errorf("the %v nullifier has no input, use the Semaphore circuit", circuit.Mode)
+	}
+	semaphore := Semaphore{
+		Secret:              circuit.Secret,
+		MerkleProofLength:   circuit.MerkleProofLength,
+		MerkleProofIndices:  circuit.MerkleProofIndices,
+		MerkleProofSiblings: circuit.MerkleProofSiblings,
+		Message:             circuit.Message,
+		Scope:               circuit.Scope,
+		DummySquare:         circuit.DummySquare,
+		Nullifier:           circuit.Nullifier,
+	}
+	_, merkleRoot, err := semaphore.define(api, nil, nullifierInputs)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(circuit.MerkleRoot, merkleRoot)
+	return nil
+}
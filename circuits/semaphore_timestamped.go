@@ -0,0 +1,43 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// SemaphoreTimestamped is the Semaphore circuit with the unix time at which the proof was
+// generated as a public input. The timestamp must fit in 64 bits, it is bound to the proof
+// as every public input of a groth16 proof
+type SemaphoreTimestamped struct {
+	Secret              frontend.Variable
+	MerkleProofLength   frontend.Variable
+	MerkleProofIndices  [MAX_DEPTH]frontend.Variable
+	MerkleProofSiblings [MAX_DEPTH]frontend.Variable
+	Message             frontend.Variable `gnark:",public"`
+	Scope               frontend.Variable `gnark:",public"`
+	DummySquare         frontend.Variable `gnark:",public"`
+	MerkleRoot          frontend.Variable `gnark:",public"`
+	Nullifier           frontend.Variable `gnark:",public"`
+	Timestamp           frontend.Variable `gnark:",public"`
+}
+
+func (circuit *SemaphoreTimestamped) Define(api frontend.API) error {
+	semaphore := Semaphore{
+		Secret:              circuit.Secret,
+		MerkleProofLength:   circuit.MerkleProofLength,
+		MerkleProofIndices:  circuit.MerkleProofIndices,
+		MerkleProofSiblings: circuit.MerkleProofSiblings,
+		Message:             circuit.Message,
+		Scope:               circuit.Scope,
+		DummySquare:         circuit.DummySquare,
+		Nullifier:           circuit.Nullifier,
+	}
+	_, merkleRoot, err := semaphore.define(api, nil, nil)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(circuit.MerkleRoot, merkleRoot)
+
+	// The timestamp fits in 64 bits
+	api.ToBinary(circuit.Timestamp, 64)
+	return nil
+}
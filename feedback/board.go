@@ -0,0 +1,214 @@
+package feedback
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+)
+
+// Errors of the boards, use errors.Is to check them
+var (
+	ErrUnknownTopic   = errors.New("unknown topic")
+	ErrAlreadyPosted  = errors.New("the member has already posted on this topic")
+	ErrUnknownRoot    = errors.New("the merkle root has never been a root of the group")
+	ErrTextMismatch   = errors.New("the message isn't the hash of the text")
+	ErrTopicMismatch  = errors.New("the scope isn't the scope of the topic")
+	ErrTopicDuplicate = errors.New("the topic already exists")
+)
+
+// Post is an anonymous feedback of a member on a topic, stored with its proof
+// so that anyone with the verifying key can verify it again
+type Post struct {
+	Topic string                     `json:"topic"`
+	Text  string                     `json:"text"`
+	Proof semaphore.JSSemaphoreProof `json:"proof"`
+}
+
+// Board is an anonymous feedback board of a group of members, each member
+// may post once per topic
+type Board struct {
+	mu     sync.Mutex
+	id     string
+	s      *semaphore.Semaphore
+	vk     groth16.VerifyingKey
+	topics map[string]bool
+	scopes map[string]bool // scopes of the topics, by decimal string
+	posts  map[string][]Post
+}
+
+// TopicScope returns the scope of a topic of the board `boardID`
+func TopicScope(boardID, topic string) *big.Int {
+	return semaphore.HashToField([]byte(fmt.Sprintf("%d:%s/%s", len(boardID), boardID, topic)))
+}
+
+// TextMessage returns the message of a post
+func TextMessage(text string) *big.Int {
+	return semaphore.HashToField([]byte(text))
+}
+
+// NewBoard creates a board using an already setup Semaphore circuit
+func NewBoard(id string, ccs constraint.ConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey) (*Board, error) {
+	if id == "" {
+		return nil, fmt.Errorf("missing board id")
+	}
+	b := &Board{
+		id:     id,
+		s:      semaphore.NewSemaphoreWithKeys(ccs, pk, vk),
+		vk:     vk,
+		topics: make(map[string]bool),
+		scopes: make(map[string]bool),
+		posts:  make(map[string][]Post),
+	}
+	// Called by VerifyProof, with the lock of the board held
+	b.s.SetScopeValidator(func(scope *big.Int) bool {
+		return b.scopes[scope.String()]
+	})
+	return b, nil
+}
+
+// ID returns the ID of the board
+func (b *Board) ID() string {
+	return b.id
+}
+
+// AddMembers registers identity commitments as members of the board
+func (b *Board) AddMembers(idcs []*big.Int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, idc := range idcs {
+		if b.s.IndexOf(idc) != -1 {
+			return fmt.Errorf("duplicated member %v", idc)
+		}
+	}
+	return b.s.AddMembers(idcs)
+}
+
+// MerkleProof returns the merkle proof of a member, used to generate its posts
+func (b *Board) MerkleProof(idc *big.Int) (leanIMT.MerkleProof, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	idx := b.s.IndexOf(idc)
+	if idx == -1 {
+		return leanIMT.MerkleProof{}, semaphore.ErrMemberNotFound
+	}
+	return b.s.GenerateMerkleProof(idx)
+}
+
+// AddTopic opens a topic
+func (b *Board) AddTopic(topic string) error {
+	if topic == "" {
+		return fmt.Errorf("missing topic")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.topics[topic] {
+		return ErrTopicDuplicate
+	}
+	b.topics[topic] = true
+	b.scopes[TopicScope(b.id, topic).String()] = true
+	return nil
+}
+
+// NewPost generates the post of a member on a topic of the board `boardID`
+func NewPost(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	secret *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	boardID, topic, text string,
+) (*Post, error) {
+	scope := TopicScope(boardID, topic)
+	nullifier, err := semaphore.MimcHash([]*big.Int{scope, secret})
+	if err != nil {
+		return nil, err
+	}
+	sProof := semaphore.SemaphoreProof{
+		MerkleRoot: merkleProof.Root,
+		Nullifier:  nullifier,
+		Message:    TextMessage(text),
+		Scope:      scope,
+	}
+	proof, err := semaphore.GenerateSemaphoreProof(ccs, pk, secret, merkleProof, sProof)
+	if err != nil {
+		return nil, err
+	}
+	depth := max(len(merkleProof.Siblings), semaphore.MIN_DEPTH)
+	return &Post{Topic: topic, Text: text, Proof: semaphore.ToJSProof(proof, sProof, depth)}, nil
+}
+
+// Publish verifies a post against the current root of the group and stores it
+func (b *Board) Publish(post Post) error {
+	proof, sProof, _, err := semaphore.FromJSProof(post.Proof)
+	if err != nil {
+		return err
+	}
+	if err := checkPost(b.id, post, sProof); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.topics[post.Topic] {
+		return ErrUnknownTopic
+	}
+	if err := b.s.VerifyProof(proof, sProof); err != nil {
+		if errors.Is(err, semaphore.ErrNullifierUsed) {
+			return fmt.Errorf("%w: %w", ErrAlreadyPosted, err)
+		}
+		return err
+	}
+	b.posts[post.Topic] = append(b.posts[post.Topic], post)
+	return nil
+}
+
+// Posts returns the posts of a topic
+func (b *Board) Posts(topic string) []Post {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Post{}, b.posts[topic]...)
+}
+
+// Verify verifies a stored post again, its merkle root must be
+// one of the roots the group has had
+func (b *Board) Verify(post Post) error {
+	if err := VerifyPost(b.vk, b.id, post); err != nil {
+		return err
+	}
+	root, _ := new(big.Int).SetString(post.Proof.MerkleTreeRoot, 10)
+	for _, e := range b.s.GetEventLog().Events() {
+		if e.Root.Cmp(root) == 0 {
+			return nil
+		}
+	}
+	return ErrUnknownRoot
+}
+
+// VerifyPost verifies that a post was made on a topic of the board `boardID` by a
+// member of the group whose root is in the proof, without checking the root
+func VerifyPost(vk groth16.VerifyingKey, boardID string, post Post) error {
+	proof, sProof, _, err := semaphore.FromJSProof(post.Proof)
+	if err != nil {
+		return err
+	}
+	if err := checkPost(boardID, post, sProof); err != nil {
+		return err
+	}
+	return semaphore.VerifySemaphoreProof(vk, proof, sProof)
+}
+
+// checkPost checks that the scope and the message of a post match its topic and its text
+func checkPost(boardID string, post Post, sProof semaphore.SemaphoreProof) error {
+	if sProof.Scope.Cmp(TopicScope(boardID, post.Topic)) != 0 {
+		return fmt.Errorf("%w: %w", ErrTopicMismatch, semaphore.ErrInvalidScope)
+	}
+	if sProof.Message.Cmp(TextMessage(post.Text)) != 0 {
+		return fmt.Errorf("%w: %w", ErrTextMismatch, semaphore.ErrInvalidMessage)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package feedback
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/require"
+)
+
+// newMembers returns the secrets and the identity commitments of `n` members
+func newMembers(t *testing.T, n int) ([]*big.Int, []*big.Int) {
+	secrets, idcs := []*big.Int{}, []*big.Int{}
+	for i := 0; i < n; i++ {
+		id, err := identity.New()
+		require.NoError(t, err)
+		secrets = append(secrets, id.Secret())
+		idcs = append(idcs, id.Commitment())
+	}
+	return secrets, idcs
+}
+
+// TestBoard checks posting once per topic and the public verification of the stored posts
+func TestBoard(t *testing.T) {
+	ccs, pk, vk, err := semaphore.SetupCircuit()
+	require.NoError(t, err)
+	b, err := NewBoard("acme", ccs, pk, vk)
+	require.NoError(t, err)
+	secrets, idcs := newMembers(t, 4)
+	require.NoError(t, b.AddMembers(idcs))
+	require.NoError(t, b.AddTopic("culture"))
+	require.NoError(t, b.AddTopic("tooling"))
+	require.ErrorIs(t, b.AddTopic("culture"), ErrTopicDuplicate)
+
+	post := func(i int, topic, text string) *Post {
+		merkleProof, err := b.MerkleProof(idcs[i])
+		require.NoError(t, err)
+		p, err := NewPost(ccs, pk, secrets[i], merkleProof, b.ID(), topic, text)
+		require.NoError(t, err)
+		return p
+	}
+
+	// A member posts once per topic
+	p := post(0, "culture", "More remote days please")
+	require.NoError(t, b.Publish(*p))
+	require.ErrorIs(t, b.Publish(*post(0, "culture", "Also, better coffee")), ErrAlreadyPosted)
+	require.NoError(t, b.Publish(*post(0, "tooling", "The CI is slow")))
+	require.NoError(t, b.Publish(*post(1, "culture", "Great team")))
+	require.ErrorIs(t, b.Publish(*post(2, "hiring", "...")), ErrUnknownTopic)
+
+	// The same post with an aliased nullifier, equal modulo r, is rejected
+	aliased := *p
+	nullifier, _ := new(big.Int).SetString(p.Proof.Nullifier, 10)
+	aliased.Proof.Nullifier = new(big.Int).Add(nullifier, fr.Modulus()).String()
+	require.Error(t, b.Publish(aliased))
+	require.Error(t, VerifyPost(vk, "acme", aliased))
+
+	// Tampered posts are rejected
+	tampered := *post(2, "culture", "Good")
+	tampered.Text = "Bad"
+	require.ErrorIs(t, b.Publish(tampered), ErrTextMismatch)
+	tampered.Text, tampered.Topic = "Good", "tooling"
+	require.ErrorIs(t, b.Publish(tampered), ErrTopicMismatch)
+	require.Len(t, b.Posts("culture"), 2)
+
+	// The stored posts can be verified again after the group changes
+	_, newIdcs := newMembers(t, 1)
+	require.NoError(t, b.AddMembers(newIdcs))
+	data, err := json.Marshal(b.Posts("culture"))
+	require.NoError(t, err)
+	var posts []Post
+	require.NoError(t, json.Unmarshal(data, &posts))
+	require.Equal(t, p.Text, posts[0].Text)
+	for _, post := range posts {
+		require.NoError(t, VerifyPost(vk, "acme", post))
+		require.NoError(t, b.Verify(post))
+	}
+	require.ErrorIs(t, VerifyPost(vk, "other", posts[0]), ErrTopicMismatch)
+
+	// Posts of another group are rejected
+	other, err := NewBoard("acme", ccs, pk, vk)
+	require.NoError(t, err)
+	otherSecrets, otherIdcs := newMembers(t, 2)
+	require.NoError(t, other.AddMembers(otherIdcs))
+	merkleProof, err := other.MerkleProof(otherIdcs[0])
+	require.NoError(t, err)
+	foreign, err := NewPost(ccs, pk, otherSecrets[0], merkleProof, "acme", "culture", "Hello")
+	require.NoError(t, err)
+	require.NoError(t, VerifyPost(vk, "acme", *foreign))
+	require.ErrorIs(t, b.Verify(*foreign), ErrUnknownRoot)
+	require.ErrorIs(t, b.Publish(*foreign), semaphore.ErrInvalidMerkleRoot)
+}
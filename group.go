@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+)
+
+// runGroup handles the `group` subcommands
+func runGroup(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing group command (create, add, remove, root, proof)")
+	}
+
+	fs := flag.NewFlagSet("group "+args[0], flag.ContinueOnError)
+	groupPath := fs.String("group", "group.json", "group file")
+
+	switch args[0] {
+	case "create":
+		members := fs.String("members", "", "comma separated identity commitments")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		leaves := []*big.Int{}
+		if *members != "" {
+			var err error
+			leaves, err = parseBigInts(strings.Split(*members, ","))
+			if err != nil {
+				return err
+			}
+		}
+		imt, err := leanIMT.NewLeanIMT(semaphore.MimcHash, leaves)
+		if err != nil {
+			return err
+		}
+		return saveGroup(*groupPath, imt)
+
+	case "add":
+		idc := commitmentFlags(fs)
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		commitment, err := idc()
+		if err != nil {
+			return err
+		}
+		imt, err := loadGroup(*groupPath)
+		if err != nil {
+			return err
+		}
+		if imt.Size() != 0 && imt.IndexOf(commitment) != -1 {
+			return fmt.Errorf("the provided identity commitment is already a member")
+		}
+		if err := imt.Insert(commitment); err != nil {
+			return err
+		}
+		return saveGroup(*groupPath, imt)
+
+	case "remove":
+		idc := commitmentFlags(fs)
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		commitment, err := idc()
+		if err != nil {
+			return err
+		}
+		imt, err := loadGroup(*groupPath)
+		if err != nil {
+			return err
+		}
+		idx, err := indexOf(imt, commitment)
+		if err != nil {
+			return err
+		}
+		// Removed members are replaced by a zero leaf, as in Semaphore.RemoveMember
+		if err := imt.Update(big.NewInt(0), idx); err != nil {
+			return err
+		}
+		return saveGroup(*groupPath, imt)
+
+	case "root":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		imt, err := loadGroup(*groupPath)
+		if err != nil {
+			return err
+		}
+		if imt.Size() == 0 {
+			return fmt.Errorf("the group is empty")
+		}
+		return writeJSON("", stdout, RootFile{
+			Root:  imt.Root().String(),
+			Depth: imt.Depth(),
+			Size:  imt.Size(),
+		})
+
+	case "proof":
+		idc := commitmentFlags(fs)
+		index := fs.Int("index", -1, "index of the member (instead of its commitment)")
+		out := fs.String("out", "", "output merkle proof file (default: stdout)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		imt, err := loadGroup(*groupPath)
+		if err != nil {
+			return err
+		}
+		idx := *index
+		if idx == -1 {
+			commitment, err := idc()
+			if err != nil {
+				return err
+			}
+			idx, err = indexOf(imt, commitment)
+			if err != nil {
+				return err
+			}
+		}
+		if idx < 0 || idx >= imt.Size() {
+			return fmt.Errorf("invalid index %d", idx)
+		}
+		merkleProof, err := imt.GenerateProof(idx)
+		if err != nil {
+			return err
+		}
+		return writeJSON(*out, stdout, MerkleProofFile{
+			Index:    idx,
+			Leaf:     merkleProof.Node.String(),
+			Root:     merkleProof.Root.String(),
+			Path:     merkleProof.Path,
+			Siblings: formatBigInts(merkleProof.Siblings),
+		})
+
+	default:
+		return fmt.Errorf("unknown group command %q", args[0])
+	}
+}
+
+// commitmentFlags registers the flags selecting an identity commitment, either
+// directly or from an identity file, and returns a function resolving it
+func commitmentFlags(fs *flag.FlagSet) func() (*big.Int, error) {
+	commitment := fs.String("commitment", "", "identity commitment")
+	identityPath := fs.String("identity", "", "identity file (instead of -commitment)")
+	return func() (*big.Int, error) {
+		switch {
+		case *commitment != "":
+			return parseBigInt(*commitment)
+		case *identityPath != "":
+			secret, err := loadIdentity(*identityPath)
+			if err != nil {
+				return nil, err
+			}
+			return semaphore.MimcHash([]*big.Int{secret})
+		default:
+			return nil, fmt.Errorf("either -commitment or -identity is required")
+		}
+	}
+}
+
+// indexOf returns the index of a member in the group
+func indexOf(imt *leanIMT.LeanIMT, commitment *big.Int) (int, error) {
+	if imt.Size() != 0 {
+		if idx := imt.IndexOf(commitment); idx != -1 {
+			return idx, nil
+		}
+	}
+	return -1, fmt.Errorf("the provided identity commitment doesn't exist")
+}
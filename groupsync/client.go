@@ -0,0 +1,183 @@
+package groupsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+)
+
+// Checkpoint is the synced state of a group saved between restarts
+type Checkpoint struct {
+	Next   uint64     `json:"next"` // sequence number of the next event to apply
+	Leaves []*big.Int `json:"leaves"`
+	Root   *big.Int   `json:"root,omitempty"`
+}
+
+// Client keeps a local copy of a group tree in sync with a source of events,
+// checking every reported root instead of trusting the source
+type Client struct {
+	source     Source
+	checkpoint string
+	imt        *leanIMT.LeanIMT
+	next       uint64
+}
+
+// NewClient returns a client syncing from `source`. If `checkpoint` is not empty,
+// the client resumes from the checkpoint file if it exists and saves it after each sync
+func NewClient(source Source, checkpoint string) (*Client, error) {
+	c := &Client{source: source, checkpoint: checkpoint}
+	cp := Checkpoint{}
+	if checkpoint != "" {
+		data, err := os.ReadFile(checkpoint)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &cp); err != nil {
+				return nil, fmt.Errorf("invalid checkpoint: %w", err)
+			}
+		}
+	}
+	if err := c.restore(cp); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Sync applies the new events of the source and returns the number of applied events.
+// If an event doesn't match the local tree, the tree is left at the last valid event
+func (c *Client) Sync(ctx context.Context) (int, error) {
+	events, err := c.source.Events(ctx, c.next)
+	if err != nil {
+		return 0, err
+	}
+
+	start := c.state()
+	applied := 0
+	for _, e := range events {
+		if e.Seq != c.next {
+			err = fmt.Errorf("expected event %d, got event %d", c.next, e.Seq)
+			break
+		}
+		if err = semaphore.ApplyEvent(c.imt, e); err != nil {
+			// The tree may be partially updated, replay the valid events from the start
+			if restoreErr := c.rollback(start, events[:applied]); restoreErr != nil {
+				return applied, restoreErr
+			}
+			break
+		}
+		c.next++
+		applied++
+	}
+
+	if applied != 0 {
+		if saveErr := c.save(); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	return applied, err
+}
+
+// Next returns the sequence number of the next event to apply
+func (c *Client) Next() uint64 {
+	return c.next
+}
+
+// Size returns the number of leaves of the local tree
+func (c *Client) Size() int {
+	return c.imt.Size()
+}
+
+// Root returns the root of the local tree, or nil if the group is empty
+func (c *Client) Root() *big.Int {
+	if c.imt.Size() == 0 {
+		return nil
+	}
+	return new(big.Int).Set(c.imt.Root())
+}
+
+// IndexOf returns the index of an identity commitment in the local tree, or -1
+func (c *Client) IndexOf(idc *big.Int) int {
+	if c.imt.Size() == 0 {
+		return -1
+	}
+	return c.imt.IndexOf(idc)
+}
+
+// GenerateMerkleProof returns the merkle proof of the leaf at `idx` of the local tree
+func (c *Client) GenerateMerkleProof(idx int) (leanIMT.MerkleProof, error) {
+	if idx < 0 || idx >= c.imt.Size() {
+		return leanIMT.MerkleProof{}, fmt.Errorf("invalid index")
+	}
+	return c.imt.GenerateProof(idx)
+}
+
+// state returns the current state of the client
+func (c *Client) state() Checkpoint {
+	cp := Checkpoint{Next: c.next, Leaves: []*big.Int{}}
+	if c.imt.Size() != 0 {
+		cp.Leaves = append(cp.Leaves, c.imt.Nodes[0]...)
+		cp.Root = new(big.Int).Set(c.imt.Root())
+	}
+	return cp
+}
+
+// restore rebuilds the local tree from a checkpoint
+func (c *Client) restore(cp Checkpoint) error {
+	imt, err := leanIMT.NewLeanIMT(semaphore.MimcHash, cp.Leaves)
+	if err != nil {
+		return err
+	}
+	if cp.Root != nil && (imt.Size() == 0 || imt.Root().Cmp(cp.Root) != 0) {
+		return fmt.Errorf("the checkpoint root doesn't match its leaves")
+	}
+	c.imt = imt
+	c.next = cp.Next
+	return nil
+}
+
+// rollback restores a checkpoint and applies already checked events
+func (c *Client) rollback(cp Checkpoint, events []semaphore.GroupEvent) error {
+	if err := c.restore(cp); err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := semaphore.ApplyEvent(c.imt, e); err != nil {
+			return err
+		}
+		c.next++
+	}
+	return nil
+}
+
+// save writes the checkpoint file atomically
+func (c *Client) save() error {
+	if c.checkpoint == "" {
+		return nil
+	}
+	data, err := json.Marshal(c.state())
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.checkpoint), filepath.Base(c.checkpoint)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.checkpoint)
+}
@@ -0,0 +1,131 @@
+package groupsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/server"
+	"github.com/stretchr/testify/require"
+)
+
+// randomCommitments returns `n` random identity commitments
+func randomCommitments(t *testing.T, n int) []*big.Int {
+	res := []*big.Int{}
+	for i := 0; i < n; i++ {
+		id, err := identity.New()
+		require.NoError(t, err)
+		res = append(res, id.Commitment())
+	}
+	return res
+}
+
+// TestFileSync checks that a client syncs from an event file, resumes from
+// its checkpoint and rejects inconsistent events
+func TestFileSync(t *testing.T) {
+	dir := t.TempDir()
+	eventsFile := filepath.Join(dir, "events.jsonl")
+	checkpoint := filepath.Join(dir, "checkpoint.json")
+	ctx := context.Background()
+
+	s := semaphore.NewSemaphoreWithKeys(nil, nil, nil)
+	idcs := randomCommitments(t, 8)
+	require.NoError(t, s.AddMembers(idcs[:4]))
+	require.NoError(t, s.AddMember(idcs[4]))
+	require.NoError(t, AppendEventsFile(eventsFile, s.GetEventLog().Events()))
+
+	c, err := NewClient(FileSource{Path: eventsFile}, checkpoint)
+	require.NoError(t, err)
+	n, err := c.Sync(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, s.GetGroup().Root(), c.Root())
+
+	// Resume after a restart
+	next := uint64(s.GetEventLog().Len())
+	require.NoError(t, s.UpdateMember(idcs[1], idcs[5]))
+	require.NoError(t, s.RemoveMember(idcs[2], nil))
+	require.NoError(t, AppendEventsFile(eventsFile, s.GetEventLog().Since(next)))
+
+	c, err = NewClient(FileSource{Path: eventsFile}, checkpoint)
+	require.NoError(t, err)
+	require.Equal(t, next, c.Next())
+	require.Equal(t, 5, c.Size())
+	n, err = c.Sync(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, s.GetGroup().Root(), c.Root())
+	require.Equal(t, 1, c.IndexOf(idcs[5]))
+
+	merkleProof, err := c.GenerateMerkleProof(1)
+	require.NoError(t, err)
+	require.True(t, s.GetGroup().VerifyProof(&merkleProof))
+
+	// A reported root that doesn't match stops the sync at the last valid event
+	next = uint64(s.GetEventLog().Len())
+	require.NoError(t, s.AddMember(idcs[6]))
+	require.NoError(t, s.AddMember(idcs[7]))
+	events := s.GetEventLog().Since(next)
+	validRoot := events[0].Root
+	events[1].Root = big.NewInt(1)
+	require.NoError(t, AppendEventsFile(eventsFile, events))
+
+	n, err = c.Sync(ctx)
+	require.ErrorContains(t, err, "doesn't match the recorded root")
+	require.Equal(t, 1, n)
+	require.Equal(t, validRoot, c.Root())
+	require.Equal(t, 6, c.Size())
+	require.Equal(t, next+1, c.Next())
+	_, err = c.Sync(ctx)
+	require.Error(t, err)
+	require.Equal(t, 6, c.Size())
+}
+
+// TestHTTPSync checks that a client syncs from the events endpoint of the HTTP API
+func TestHTTPSync(t *testing.T) {
+	ts := httptest.NewServer(server.NewServer(nil, nil, nil).Handler())
+	defer ts.Close()
+
+	post := func(path string, body any) {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		res, err := http.Post(ts.URL+path, "application/json", bytes.NewReader(data))
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+	}
+	idcs := randomCommitments(t, 4)
+	post("/groups", server.CreateGroupRequest{ID: "g", Members: []string{idcs[0].String(), idcs[1].String()}})
+
+	c, err := NewClient(HTTPSource{URL: ts.URL + "/groups/g/events"}, "")
+	require.NoError(t, err)
+	n, err := c.Sync(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	post("/groups/g/members", server.MemberRequest{Commitment: idcs[2].String()})
+	post("/groups/g/members", server.MemberRequest{Commitment: idcs[3].String()})
+	n, err = c.Sync(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	var group server.GroupResponse
+	res, err := http.Get(ts.URL + "/groups/g")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&group))
+	require.Equal(t, group.Root, c.Root().String())
+
+	// Unknown groups fail to sync
+	c, err = NewClient(HTTPSource{URL: ts.URL + "/groups/unknown/events"}, "")
+	require.NoError(t, err)
+	_, err = c.Sync(context.Background())
+	require.Error(t, err)
+}
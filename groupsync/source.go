@@ -0,0 +1,114 @@
+package groupsync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+)
+
+// Source provides the membership events of a group
+type Source interface {
+	// Events returns the events from sequence number `since`, in order
+	Events(ctx context.Context, since uint64) ([]semaphore.GroupEvent, error)
+}
+
+// FileSource reads the events of a group from a file holding one JSON event per line
+type FileSource struct {
+	Path string
+}
+
+// Events returns the events of the file from sequence number `since`
+func (src FileSource) Events(ctx context.Context, since uint64) ([]semaphore.GroupEvent, error) {
+	f, err := os.Open(src.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events := []semaphore.GroupEvent{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e semaphore.GroupEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", src.Path, line, err)
+		}
+		if e.Seq >= since {
+			events = append(events, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// AppendEventsFile appends events to a file read by a FileSource
+func AppendEventsFile(path string, events []semaphore.GroupEvent) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// HTTPSource fetches the events of a group from the events endpoint
+// of the HTTP API, e.g. http://localhost:8080/groups/{id}/events
+type HTTPSource struct {
+	URL    string
+	Client *http.Client // defaults to http.DefaultClient
+}
+
+// Events fetches the events from sequence number `since`
+func (src HTTPSource) Events(ctx context.Context, since uint64) ([]semaphore.GroupEvent, error) {
+	u, err := url.Parse(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("since", strconv.FormatUint(since, 10))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := src.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch events: %s", res.Status)
+	}
+
+	events := []semaphore.GroupEvent{}
+	if err := json.NewDecoder(res.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("failed to decode events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"golang.org/x/term"
+)
+
+// PASSPHRASE_ENV is the environment variable holding the passphrase of the identity commands.
+// Passphrases aren't read from flags, which show in the process list and the shell history
+const PASSPHRASE_ENV = "SEMAPHORE_PASSPHRASE"
+
+// MNEMONIC_ENV is the environment variable holding the mnemonic of `identity recover`,
+// which is read like the passphrases
+const MNEMONIC_ENV = "SEMAPHORE_MNEMONIC"
+
+// stdin is shared by the reads of the mnemonic and the passphrase, which are successive lines
+var stdin = bufio.NewReader(os.Stdin)
+
+// runIdentity handles the `identity` subcommands
+func runIdentity(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing identity command (new, recover, export, encrypt, decrypt, rotate)")
+	}
+
+	switch args[0] {
+	case "new":
+		fs := flag.NewFlagSet("identity new", flag.ContinueOnError)
+		out := fs.String("out", "", "output identity file (default: stdout)")
+		mnemonic := fs.Bool("mnemonic", false, "derive the identity from a new BIP-39 mnemonic, stored in the identity file")
+		withPassphrase := fs.Bool("with-passphrase", false, "read a BIP-39 passphrase, with -mnemonic")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if !*mnemonic {
+			id, err := identity.New()
+			if err != nil {
+				return err
+			}
+			return writeJSON(*out, stdout, newIdentityFile(id, ""))
+		}
+		passphrase := ""
+		if *withPassphrase {
+			var err error
+			if passphrase, err = readPassphrase("BIP-39 passphrase", true); err != nil {
+				return err
+			}
+		}
+		id, words, err := identity.NewMnemonic(passphrase)
+		if err != nil {
+			return err
+		}
+		return writeJSON(*out, stdout, newIdentityFile(id, words))
+
+	case "recover":
+		fs := flag.NewFlagSet("identity recover", flag.ContinueOnError)
+		withPassphrase := fs.Bool("with-passphrase", false, "read the BIP-39 passphrase of the mnemonic")
+		out := fs.String("out", "", "output identity file (default: stdout)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		mnemonic, err := readSecret(MNEMONIC_ENV, "mnemonic", "BIP-39 mnemonic", false)
+		if err != nil {
+			return err
+		}
+		passphrase := ""
+		if *withPassphrase {
+			if passphrase, err = readPassphrase("BIP-39 passphrase", false); err != nil {
+				return err
+			}
+		}
+		id, err := identity.FromMnemonic(mnemonic, passphrase)
+		if err != nil {
+			return err
+		}
+		return writeJSON(*out, stdout, newIdentityFile(id, mnemonic))
+
+	case "export":
+		fs := flag.NewFlagSet("identity export", flag.ContinueOnError)
+		identityPath := fs.String("identity", "identity.json", "identity file")
+		out := fs.String("out", "", "output commitment file (default: stdout)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		secret, err := loadIdentity(*identityPath)
+		if err != nil {
+			return err
+		}
+		id, err := identity.FromSecret(secret)
+		if err != nil {
+			return err
+		}
+		return writeJSON(*out, stdout, CommitmentFile{Commitment: id.Commitment().String()})
+
+	case "encrypt":
+		fs := flag.NewFlagSet("identity encrypt", flag.ContinueOnError)
+		identityPath := fs.String("identity", "identity.json", "identity file")
+		light := fs.Bool("light", false, "use light scrypt parameters, faster but weaker")
+		out := fs.String("out", "keystore.json", "output keystore file")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		passphrase, err := readPassphrase("Keystore passphrase", true)
+		if err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return fmt.Errorf("missing passphrase")
+		}
+		secret, err := loadIdentity(*identityPath)
+		if err != nil {
+			return err
+		}
+		id, err := identity.FromSecret(secret)
+		if err != nil {
+			return err
+		}
+		scryptN := identity.STANDARD_SCRYPT_N
+		if *light {
+			scryptN = identity.LIGHT_SCRYPT_N
+		}
+		return identity.SaveKeystore(*out, id, passphrase, scryptN)
+
+	case "decrypt":
+		fs := flag.NewFlagSet("identity decrypt", flag.ContinueOnError)
+		keystorePath := fs.String("keystore", "keystore.json", "keystore file")
+		out := fs.String("out", "", "output identity file (default: stdout)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		passphrase, err := readPassphrase("Keystore passphrase", false)
+		if err != nil {
+			return err
+		}
+		id, err := identity.LoadKeystore(*keystorePath, passphrase)
+		if err != nil {
+			return err
+		}
+		return writeJSON(*out, stdout, newIdentityFile(id, ""))
+
+	case "rotate":
+		fs := flag.NewFlagSet("identity rotate", flag.ContinueOnError)
+		identityPath := fs.String("identity", "identity.json", "identity file")
+		groupPath := fs.String("group", "", "group file in which the commitment is swapped (optional)")
+		out := fs.String("out", "", "output file of the new identity (default: stdout)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		secret, err := loadIdentity(*identityPath)
+		if err != nil {
+			return err
+		}
+		old, err := identity.FromSecret(secret)
+		if err != nil {
+			return err
+		}
+		r, err := identity.Rotate(old)
+		if err != nil {
+			return err
+		}
+		if *groupPath != "" {
+			imt, err := loadGroup(*groupPath)
+			if err != nil {
+				return err
+			}
+			idx, err := indexOf(imt, old.Commitment())
+			if err != nil {
+				return err
+			}
+			if err := imt.Update(r.New.Commitment(), idx); err != nil {
+				return err
+			}
+			// Write the new identity first, so that it is never lost
+			if err := writeJSON(*out, stdout, newIdentityFile(r.New, "")); err != nil {
+				return err
+			}
+			return saveGroup(*groupPath, imt)
+		}
+		return writeJSON(*out, stdout, newIdentityFile(r.New, ""))
+
+	default:
+		return fmt.Errorf("unknown identity command %q", args[0])
+	}
+}
+
+// readPassphrase returns the passphrase of $SEMAPHORE_PASSPHRASE if it is set. Otherwise it prompts
+// for the passphrase on the terminal without echoing it, twice if `confirm` is set, or reads the
+// next line of stdin if it isn't a terminal
+func readPassphrase(prompt string, confirm bool) (string, error) {
+	return readSecret(PASSPHRASE_ENV, "passphrase", prompt, confirm)
+}
+
+// readSecret reads the secret `name` from the environment variable `env`, the terminal or stdin,
+// as readPassphrase does
+func readSecret(env, name, prompt string, confirm bool) (string, error) {
+	if secret, ok := os.LookupEnv(env); ok {
+		return secret, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := stdin.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read the %s: %w", name, err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	read := func(prompt string) (string, error) {
+		fmt.Fprintf(os.Stderr, "%s: ", prompt)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the %s: %w", name, err)
+		}
+		return string(secret), nil
+	}
+	secret, err := read(prompt)
+	if err != nil || !confirm {
+		return secret, err
+	}
+	again, err := read("Repeat the " + name)
+	if err != nil {
+		return "", err
+	}
+	if again != secret {
+		return "", fmt.Errorf("the %ss don't match", name)
+	}
+	return secret, nil
+}
@@ -0,0 +1,131 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// Errors of the signatures, use errors.Is to check them
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	ErrClaimMismatch    = errors.New("the claim isn't the claim of the commitment")
+	ErrInvalidProof     = errors.New("the claim doesn't prove that the key and the commitment share their secret")
+)
+
+// NONCE_TAG separates the hash of the signature nonces from the other uses of the secret
+const NONCE_TAG = "semaphore-eddsa-nonce"
+
+// KeyClaim is the claim of an identity commitment by the holder of an EdDSA public key,
+// with the groth16 proof that both share their secret: the commitment is MiMC(secret)
+// and the key is secret·B8. Only the holder of the secret can prove it, so that a valid
+// claim shows that the key controls the commitment. Copying the claim of another member
+// gains nothing, as the challenges are signed by the key
+type KeyClaim struct {
+	Commitment *big.Int
+	PublicKey  *babyjub.PublicKey
+	Proof      *groth16_bn254.Proof
+}
+
+// PublicKey returns the Baby Jubjub EdDSA public key of the identity, secret·B8.
+// The secret is the private scalar of the key, so that the circuit of the key claims
+// can relate the key to the commitment
+func (id *Identity) PublicKey() *babyjub.PublicKey {
+	return babyjub.NewPrivKeyScalar(id.secret).Public()
+}
+
+// Sign signs a message with the Baby Jubjub EdDSA over Poseidon, the signatures are
+// verified as `verifyPoseidon` of circomlibjs does. Unlike `signPoseidon`, the private
+// scalar is the secret itself rather than derived from private key bytes. The message
+// must be in the BN254 scalar field, bytes can be mapped into it with semaphore.HashToField
+func (id *Identity) Sign(message *big.Int) (*babyjub.Signature, error) {
+	if message == nil || message.Sign() < 0 || message.Cmp(ecc.BN254.ScalarField()) >= 0 {
+		return nil, fmt.Errorf("the message must be in the scalar field")
+	}
+
+	// r = H(tag, secret, message), deterministic as the nonces of EdDSA
+	secretBuf, msgBuf := utils.BigIntLEBytes(id.secret), utils.BigIntLEBytes(message)
+	rBuf := babyjub.Blake512(append(append([]byte(NONCE_TAG), secretBuf[:]...), msgBuf[:]...))
+	r := utils.SetBigIntFromLEBytes(new(big.Int), rBuf)
+	r.Mod(r, babyjub.SubOrder)
+	R8 := babyjub.NewPoint().Mul(r, babyjub.B8)
+	A := id.PublicKey().Point()
+
+	// S = r + 8·H(R8, A, message)·secret, so that S·B8 = R8 + 8·hm·A
+	hm, err := poseidon.Hash([]*big.Int{R8.X, R8.Y, A.X, A.Y, message})
+	if err != nil {
+		return nil, err
+	}
+	S := new(big.Int).Lsh(id.secret, 3)
+	S.Mul(S, hm)
+	S.Add(S, r)
+	S.Mod(S, babyjub.SubOrder)
+	return &babyjub.Signature{R8: R8, S: S}, nil
+}
+
+// VerifySignature verifies the signature of a message by the public key `pk`
+func VerifySignature(pk *babyjub.PublicKey, message *big.Int, sig *babyjub.Signature) error {
+	if pk == nil || !pk.Point().InCurve() || !pk.Point().InSubGroup() {
+		return ErrInvalidPublicKey
+	}
+	// S is checked against the subgroup order, so that signatures aren't malleable
+	if message == nil || sig == nil || sig.R8 == nil || sig.S == nil ||
+		sig.S.Sign() < 0 || sig.S.Cmp(SUBGROUP_ORDER) >= 0 || !pk.VerifyPoseidon(message, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ClaimKey returns the claim of the commitment of the identity by its public key, proven with
+// the keys of semaphore.SetupKeyClaimCircuit
+func (id *Identity) ClaimKey(ccs constraint.ConstraintSystem, pk groth16.ProvingKey) (*KeyClaim, error) {
+	key := id.PublicKey()
+	proof, err := semaphore.GenerateKeyClaimProof(ccs, pk, id.secret, key.X, key.Y)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyClaim{Commitment: id.Commitment(), PublicKey: key, Proof: proof}, nil
+}
+
+// VerifyKeyClaim verifies that the claim of `commitment` proves that its public key and
+// the commitment share their secret, `vk` is the verifying key of semaphore.SetupKeyClaimCircuit
+func VerifyKeyClaim(vk groth16.VerifyingKey, commitment *big.Int, c *KeyClaim) error {
+	if c == nil || c.Commitment == nil || commitment == nil || c.Commitment.Cmp(commitment) != 0 {
+		return ErrClaimMismatch
+	}
+	if c.PublicKey == nil || !c.PublicKey.Point().InCurve() || !c.PublicKey.Point().InSubGroup() {
+		return ErrInvalidPublicKey
+	}
+	if c.Proof == nil {
+		return ErrInvalidProof
+	}
+	if err := semaphore.VerifyKeyClaimProof(vk, c.Proof, c.Commitment, c.PublicKey.X, c.PublicKey.Y); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	return nil
+}
+
+// SignChallenge signs a challenge of a verifier, to show that the identity
+// holds the public key of a claim
+func (id *Identity) SignChallenge(challenge *big.Int) (*babyjub.Signature, error) {
+	return id.Sign(challenge)
+}
+
+// VerifyChallenge verifies that the signature of a challenge was made by
+// the public key of a claim, verified with VerifyKeyClaim
+func VerifyChallenge(c *KeyClaim, challenge *big.Int, sig *babyjub.Signature) error {
+	if c == nil {
+		return ErrClaimMismatch
+	}
+	return VerifySignature(c.PublicKey, challenge, sig)
+}
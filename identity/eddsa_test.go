@@ -0,0 +1,93 @@
+package identity
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSign checks the signatures against the vectors of circomlibjs
+func TestSign(t *testing.T) {
+	// The signatures of circomlibjs `signPoseidon` are verified
+	var key babyjub.PrivateKey
+	_, err := hex.Decode(key[:], []byte("0001020304050607080900010203040506070809000102030405060708090001"))
+	require.NoError(t, err)
+	vectorKey := key.Public()
+	require.Equal(t, "13277427435165878497778222415993513565335242147425444199013288855685581939618", vectorKey.X.String())
+	require.Equal(t, "13622229784656158136036771217484571176836296686641868549125388198837476602820", vectorKey.Y.String())
+	// The message is the bytes 0..9 in little-endian
+	message, _ := new(big.Int).SetString("42649378395939397566720", 10)
+	r8x, _ := new(big.Int).SetString("11384336176656855268977457483345535180380036354188103142384839473266348197733", 10)
+	r8y, _ := new(big.Int).SetString("15383486972088797283337779941324724402501462225528836549661220478783371668959", 10)
+	s, _ := new(big.Int).SetString("1672775540645840396591609181675628451599263765380031905495115170613215233181", 10)
+	vector := &babyjub.Signature{R8: &babyjub.Point{X: r8x, Y: r8y}, S: s}
+	require.NoError(t, VerifySignature(vectorKey, message, vector))
+
+	// The private scalar of an identity is its secret
+	id, err := FromSecret(big.NewInt(2024))
+	require.NoError(t, err)
+	pk := id.PublicKey()
+	require.Equal(t, babyjub.NewPoint().Mul(big.NewInt(2024), babyjub.B8), pk.Point())
+	sig, err := id.Sign(message)
+	require.NoError(t, err)
+	require.NoError(t, VerifySignature(pk, message, sig))
+	again, err := id.Sign(message)
+	require.NoError(t, err)
+	require.Equal(t, sig, again)
+
+	// Wrong messages, keys and malleated signatures are rejected
+	require.ErrorIs(t, VerifySignature(pk, big.NewInt(1), sig), ErrInvalidSignature)
+	other, err := New()
+	require.NoError(t, err)
+	require.ErrorIs(t, VerifySignature(other.PublicKey(), message, sig), ErrInvalidSignature)
+	malleated := &babyjub.Signature{R8: sig.R8, S: new(big.Int).Add(sig.S, SUBGROUP_ORDER)}
+	require.ErrorIs(t, VerifySignature(pk, message, malleated), ErrInvalidSignature)
+	invalid := babyjub.PublicKey{X: big.NewInt(1), Y: big.NewInt(2)}
+	require.ErrorIs(t, VerifySignature(&invalid, message, sig), ErrInvalidPublicKey)
+	_, err = id.Sign(new(big.Int).Lsh(big.NewInt(1), 256))
+	require.Error(t, err)
+}
+
+// TestKeyClaim checks the claims of commitments and the signed challenges
+func TestKeyClaim(t *testing.T) {
+	ccs, pk, vk, err := semaphore.SetupKeyClaimCircuit()
+	require.NoError(t, err)
+	id, err := New()
+	require.NoError(t, err)
+	other, err := New()
+	require.NoError(t, err)
+
+	// Noted at registration
+	c, err := id.ClaimKey(ccs, pk)
+	require.NoError(t, err)
+	require.NoError(t, VerifyKeyClaim(vk, id.Commitment(), c))
+	require.ErrorIs(t, VerifyKeyClaim(vk, other.Commitment(), c), ErrClaimMismatch)
+
+	// The key of another member can't claim the commitment
+	forged := *c
+	forged.PublicKey = other.PublicKey()
+	require.ErrorIs(t, VerifyKeyClaim(vk, id.Commitment(), &forged), ErrInvalidProof)
+	otherClaim, err := other.ClaimKey(ccs, pk)
+	require.NoError(t, err)
+	stolen := &KeyClaim{Commitment: id.Commitment(), PublicKey: other.PublicKey(), Proof: otherClaim.Proof}
+	require.ErrorIs(t, VerifyKeyClaim(vk, id.Commitment(), stolen), ErrInvalidProof)
+	_, err = semaphore.GenerateKeyClaimProof(ccs, pk, other.secret, id.PublicKey().X, id.PublicKey().Y)
+	require.Error(t, err)
+	forged = *c
+	forged.Proof = nil
+	require.ErrorIs(t, VerifyKeyClaim(vk, id.Commitment(), &forged), ErrInvalidProof)
+
+	// Later challenges
+	challenge := big.NewInt(2024)
+	sig, err := id.SignChallenge(challenge)
+	require.NoError(t, err)
+	require.NoError(t, VerifyChallenge(c, challenge, sig))
+	require.ErrorIs(t, VerifyChallenge(c, big.NewInt(2025), sig), ErrInvalidSignature)
+	sig, err = other.SignChallenge(challenge)
+	require.NoError(t, err)
+	require.ErrorIs(t, VerifyChallenge(c, challenge, sig), ErrInvalidSignature)
+}
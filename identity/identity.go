@@ -0,0 +1,124 @@
+package identity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/hkdf"
+)
+
+// SUBGROUP_ORDER is the order of the Baby Jubjub prime subgroup,
+// the Semaphore circuit only accepts secrets lower than it
+var SUBGROUP_ORDER = new(big.Int).Set(babyjub.SubOrder)
+
+// MNEMONIC_ENTROPY_BITS is the entropy of the generated mnemonics (24 words)
+const MNEMONIC_ENTROPY_BITS = 256
+
+// SEED_INFO is the HKDF info used to derive secrets from seeds
+const SEED_INFO = "semaphore-implementation-in-go identity secret"
+
+// Identity holds the secret of a member and its commitment
+type Identity struct {
+	secret     *big.Int
+	commitment *big.Int
+}
+
+// New returns an identity with a secret drawn from a cryptographically secure source
+func New() (*Identity, error) {
+	return NewFromReader(rand.Reader)
+}
+
+// NewFromReader returns an identity with a secret drawn from `r`
+func NewFromReader(r io.Reader) (*Identity, error) {
+	// A zero secret has a negligible probability, unless `r` is broken
+	for i := 0; i < 8; i++ {
+		secret, err := rand.Int(r, SUBGROUP_ORDER)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		if secret.Sign() != 0 {
+			return FromSecret(secret)
+		}
+	}
+	return nil, fmt.Errorf("failed to generate secret: the random source only returns zeros")
+}
+
+// FromSecret returns the identity of an existing secret, which must be in [1, SUBGROUP_ORDER)
+func FromSecret(secret *big.Int) (*Identity, error) {
+	if secret == nil || secret.Sign() <= 0 || secret.Cmp(SUBGROUP_ORDER) >= 0 {
+		return nil, fmt.Errorf("the secret must be between 1 and the subgroup order")
+	}
+	commitment, err := semaphore.MimcHash([]*big.Int{secret})
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{secret: new(big.Int).Set(secret), commitment: commitment}, nil
+}
+
+// FromSeed deterministically derives an identity from a secret seed, e.g. the signature
+// of a fixed message by a wallet, as `new Identity(privateKey)` does in the JavaScript
+// implementation. The seed must be kept secret and have enough entropy: anyone who knows
+// it recovers the identity.
+//
+// The secret is 64 bytes expanded from the seed with HKDF-SHA256, reduced modulo the
+// subgroup order, so that its bias is negligible
+func FromSeed(seed []byte) (*Identity, error) {
+	if len(seed) == 0 {
+		return nil, fmt.Errorf("empty seed")
+	}
+	buf := make([]byte, 64)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, seed, nil, []byte(SEED_INFO)), buf); err != nil {
+		return nil, err
+	}
+	secret := new(big.Int).SetBytes(buf)
+	secret.Mod(secret, new(big.Int).Sub(SUBGROUP_ORDER, big.NewInt(1)))
+	secret.Add(secret, big.NewInt(1))
+	return FromSecret(secret)
+}
+
+// NewMnemonic returns a new BIP-39 mnemonic and its identity
+func NewMnemonic(passphrase string) (*Identity, string, error) {
+	entropy, err := bip39.NewEntropy(MNEMONIC_ENTROPY_BITS)
+	if err != nil {
+		return nil, "", err
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, "", err
+	}
+	id, err := FromMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+	return id, mnemonic, nil
+}
+
+// FromMnemonic derives an identity from a BIP-39 mnemonic and an optional passphrase
+func FromMnemonic(mnemonic, passphrase string) (*Identity, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	return FromSeed(seed)
+}
+
+// Secret returns the secret of the identity, used to generate semaphore proofs
+func (id *Identity) Secret() *big.Int {
+	return new(big.Int).Set(id.secret)
+}
+
+// Commitment returns the identity commitment, added to the groups
+func (id *Identity) Commitment() *big.Int {
+	return new(big.Int).Set(id.commitment)
+}
+
+// Nullifier returns the nullifier of the identity for a scope
+func (id *Identity) Nullifier(scope *big.Int) (*big.Int, error) {
+	return semaphore.MimcHash([]*big.Int{scope, id.secret})
+}
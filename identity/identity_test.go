@@ -0,0 +1,82 @@
+package identity
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew checks random identities
+func TestNew(t *testing.T) {
+	a, err := New()
+	require.NoError(t, err)
+	b, err := New()
+	require.NoError(t, err)
+	require.NotEqual(t, a.Secret(), b.Secret())
+	require.Positive(t, a.Secret().Sign())
+	require.Negative(t, a.Secret().Cmp(SUBGROUP_ORDER))
+
+	idc, err := semaphore.MimcHash([]*big.Int{a.Secret()})
+	require.NoError(t, err)
+	require.Equal(t, idc, a.Commitment())
+	nullifier, err := a.Nullifier(big.NewInt(1))
+	require.NoError(t, err)
+	expected, err := semaphore.MimcHash([]*big.Int{big.NewInt(1), a.Secret()})
+	require.NoError(t, err)
+	require.Equal(t, expected, nullifier)
+
+	_, err = NewFromReader(bytes.NewReader(make([]byte, 1024)))
+	require.Error(t, err)
+}
+
+// TestFromSecret checks the range of the secrets
+func TestFromSecret(t *testing.T) {
+	max := new(big.Int).Sub(SUBGROUP_ORDER, big.NewInt(1))
+	_, err := FromSecret(max)
+	require.NoError(t, err)
+	for _, secret := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1), SUBGROUP_ORDER} {
+		_, err := FromSecret(secret)
+		require.Error(t, err)
+	}
+}
+
+// TestFromSeed checks that seeds and mnemonics derive the same identities every time
+func TestFromSeed(t *testing.T) {
+	a, err := FromSeed([]byte("seed"))
+	require.NoError(t, err)
+	require.Equal(t, "491008195705332288895011303146209044178767941041296907283316049243709707887", a.Secret().String())
+	b, err := FromSeed([]byte("seed2"))
+	require.NoError(t, err)
+	require.NotEqual(t, a.Secret(), b.Secret())
+	_, err = FromSeed(nil)
+	require.Error(t, err)
+
+	// BIP-39 test vector
+	mnemonic := strings.Repeat("abandon ", 11) + "about"
+	seed, err := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	require.NoError(t, err)
+	fromMnemonic, err := FromMnemonic(mnemonic, "TREZOR")
+	require.NoError(t, err)
+	fromSeed, err := FromSeed(seed)
+	require.NoError(t, err)
+	require.Equal(t, fromSeed.Secret(), fromMnemonic.Secret())
+
+	_, err = FromMnemonic(strings.Repeat("abandon ", 12), "")
+	require.Error(t, err)
+
+	// Generated mnemonics
+	id, mnemonic, err := NewMnemonic("passphrase")
+	require.NoError(t, err)
+	require.Len(t, strings.Fields(mnemonic), 24)
+	recovered, err := FromMnemonic(mnemonic, "passphrase")
+	require.NoError(t, err)
+	require.Equal(t, id.Commitment(), recovered.Commitment())
+	other, err := FromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+	require.NotEqual(t, id.Commitment(), other.Commitment())
+}
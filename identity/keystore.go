@@ -0,0 +1,211 @@
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KEYSTORE_VERSION is the version of the keystore format
+const KEYSTORE_VERSION = 1
+
+// Algorithms of the keystores
+const (
+	KEYSTORE_KDF    = "scrypt"
+	KEYSTORE_CIPHER = "aes-256-gcm"
+)
+
+// Scrypt parameters, the standard ones take about a second on a laptop
+const (
+	STANDARD_SCRYPT_N = 1 << 18
+	LIGHT_SCRYPT_N    = 1 << 12
+	SCRYPT_R          = 8
+	SCRYPT_P          = 1
+	SCRYPT_DKLEN      = 32
+	// MAX_SCRYPT_N bounds the cost of decrypting untrusted keystores
+	MAX_SCRYPT_N = 1 << 20
+)
+
+// Errors of the keystores, use errors.Is to check them
+var (
+	ErrWrongPassphrase     = errors.New("could not decrypt the keystore, wrong passphrase or corrupted keystore")
+	ErrUnsupportedKeystore = errors.New("unsupported keystore")
+)
+
+// ScryptParams are the parameters of the scrypt key derivation
+type ScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// KeystoreCrypto is the encrypted secret of a keystore
+type KeystoreCrypto struct {
+	Cipher     string       `json:"cipher"`
+	CipherText string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfparams"`
+}
+
+// Keystore is an identity encrypted with a passphrase, in a versioned JSON format
+// similar to the Ethereum keystores. The commitment is public and authenticated
+// with the secret, so that the keystore can be identified without the passphrase
+type Keystore struct {
+	Version    int            `json:"version"`
+	Commitment string         `json:"commitment"`
+	Crypto     KeystoreCrypto `json:"crypto"`
+}
+
+// Encrypt encrypts an identity with a passphrase, `scryptN` is the scrypt cost
+// parameter, STANDARD_SCRYPT_N or LIGHT_SCRYPT_N
+func Encrypt(id *Identity, passphrase string, scryptN int) (*Keystore, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := ScryptParams{N: scryptN, R: SCRYPT_R, P: SCRYPT_P, DKLen: SCRYPT_DKLEN, Salt: hex.EncodeToString(salt)}
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, params, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	commitment := id.Commitment().String()
+	cipherText := gcm.Seal(nil, nonce, id.secret.FillBytes(make([]byte, 32)), []byte(commitment))
+	return &Keystore{
+		Version:    KEYSTORE_VERSION,
+		Commitment: commitment,
+		Crypto: KeystoreCrypto{
+			Cipher:     KEYSTORE_CIPHER,
+			CipherText: hex.EncodeToString(cipherText),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        KEYSTORE_KDF,
+			KDFParams:  params,
+		},
+	}, nil
+}
+
+// Decrypt decrypts the identity of a keystore
+func Decrypt(ks *Keystore, passphrase string) (*Identity, error) {
+	if ks.Version != KEYSTORE_VERSION {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedKeystore, ks.Version)
+	}
+	if ks.Crypto.Cipher != KEYSTORE_CIPHER || ks.Crypto.KDF != KEYSTORE_KDF {
+		return nil, fmt.Errorf("%w: cipher %q, kdf %q", ErrUnsupportedKeystore, ks.Crypto.Cipher, ks.Crypto.KDF)
+	}
+	if err := ks.Crypto.KDFParams.check(); err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(ks.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid salt", ErrUnsupportedKeystore)
+	}
+	nonce, err := hex.DecodeString(ks.Crypto.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrUnsupportedKeystore)
+	}
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ciphertext", ErrUnsupportedKeystore)
+	}
+
+	gcm, err := newGCM(passphrase, ks.Crypto.KDFParams, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrUnsupportedKeystore)
+	}
+	plainText, err := gcm.Open(nil, nonce, cipherText, []byte(ks.Commitment))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	id, err := FromSecret(new(big.Int).SetBytes(plainText))
+	if err != nil {
+		return nil, err
+	}
+	if id.Commitment().String() != ks.Commitment {
+		return nil, ErrWrongPassphrase
+	}
+	return id, nil
+}
+
+// SaveKeystore encrypts an identity and writes its keystore into the file at `path`
+func SaveKeystore(path string, id *Identity, passphrase string, scryptN int) error {
+	ks, err := Encrypt(id, passphrase, scryptN)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write a temporary file first, so that an existing keystore is never truncated
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadKeystore reads the keystore file at `path` and decrypts its identity
+func LoadKeystore(path, passphrase string) (*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ks Keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return Decrypt(&ks, passphrase)
+}
+
+// check checks that the scrypt parameters are supported
+func (p ScryptParams) check() error {
+	if p.N < 2 || p.N > MAX_SCRYPT_N || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("%w: scrypt n must be a power of 2 up to %d", ErrUnsupportedKeystore, MAX_SCRYPT_N)
+	}
+	if p.R != SCRYPT_R || p.P != SCRYPT_P || p.DKLen != SCRYPT_DKLEN {
+		return fmt.Errorf("%w: scrypt r, p and dklen must be %d, %d and %d", ErrUnsupportedKeystore, SCRYPT_R, SCRYPT_P, SCRYPT_DKLEN)
+	}
+	return nil
+}
+
+// newGCM derives the key of a passphrase and returns its AES-GCM cipher
+func newGCM(passphrase string, params ScryptParams, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,71 @@
+package identity
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKeystore checks encrypting and decrypting identities
+func TestKeystore(t *testing.T) {
+	id, err := New()
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	require.NoError(t, SaveKeystore(path, id, "passphrase", LIGHT_SCRYPT_N))
+
+	loaded, err := LoadKeystore(path, "passphrase")
+	require.NoError(t, err)
+	require.Equal(t, id.Secret(), loaded.Secret())
+	_, err = LoadKeystore(path, "wrong")
+	require.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// The secret isn't stored in clear
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), id.Secret().String())
+	var ks Keystore
+	require.NoError(t, json.Unmarshal(data, &ks))
+	require.Equal(t, id.Commitment().String(), ks.Commitment)
+
+	// Tampered keystores are rejected
+	other, err := New()
+	require.NoError(t, err)
+	tampered := ks
+	tampered.Commitment = other.Commitment().String()
+	_, err = Decrypt(&tampered, "passphrase")
+	require.ErrorIs(t, err, ErrWrongPassphrase)
+	tampered = ks
+	tampered.Version = 2
+	_, err = Decrypt(&tampered, "passphrase")
+	require.ErrorIs(t, err, ErrUnsupportedKeystore)
+	tampered = ks
+	tampered.Crypto.KDFParams.N = 1 << 30
+	_, err = Decrypt(&tampered, "passphrase")
+	require.ErrorIs(t, err, ErrUnsupportedKeystore)
+	_, err = Encrypt(id, "passphrase", 1000)
+	require.ErrorIs(t, err, ErrUnsupportedKeystore)
+}
+
+// TestRotation checks swapping an identity in a group
+func TestRotation(t *testing.T) {
+	s := semaphore.NewSemaphoreWithKeys(nil, nil, nil)
+	ids := []*Identity{}
+	for i := 0; i < 3; i++ {
+		id, err := New()
+		require.NoError(t, err)
+		require.NoError(t, s.AddMember(id.Commitment()))
+		ids = append(ids, id)
+	}
+
+	r, err := Rotate(ids[1])
+	require.NoError(t, err)
+	require.NotEqual(t, r.Old.Commitment(), r.New.Commitment())
+	require.NoError(t, r.Apply(s))
+	require.Equal(t, -1, s.IndexOf(ids[1].Commitment()))
+	require.Equal(t, 1, s.IndexOf(r.New.Commitment()))
+	require.ErrorIs(t, r.Apply(s), semaphore.ErrMemberNotFound)
+}
@@ -0,0 +1,27 @@
+package identity
+
+import (
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+)
+
+// Rotation replaces the identity of a member by a new one. The nullifiers of
+// the new identity differ from the old ones, so a rotated member can signal
+// again in the scopes it has already signaled in
+type Rotation struct {
+	Old *Identity
+	New *Identity
+}
+
+// Rotate returns the rotation of `old` to a new random identity
+func Rotate(old *Identity) (*Rotation, error) {
+	id, err := New()
+	if err != nil {
+		return nil, err
+	}
+	return &Rotation{Old: old, New: id}, nil
+}
+
+// Apply swaps the old commitment for the new one in a group
+func (r *Rotation) Apply(s *semaphore.Semaphore) error {
+	return s.UpdateMember(r.Old.Commitment(), r.New.Commitment())
+}
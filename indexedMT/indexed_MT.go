@@ -0,0 +1,222 @@
+package indexedMT
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// Leaf is a leaf of the indexed merkle tree. The leaves form a linked list of
+// the values sorted in increasing order, a zero NextValue ends the list
+type Leaf struct {
+	Value     *big.Int
+	NextIndex int
+	NextValue *big.Int
+}
+
+// IndexedMT is an indexed merkle tree of fixed depth, a set of values supporting
+// proofs of non-membership: a value is not in the set if it lies strictly between
+// the values of a leaf and of its next leaf. The first leaf is (0, 0, 0), so
+// that every non-zero value has a low leaf
+type IndexedMT struct {
+	Depth    int
+	Leaves   []Leaf
+	Nodes    [][]*big.Int // the non-empty nodes of each level
+	HashFunc func([]*big.Int) (*big.Int, error)
+	zeros    []*big.Int // the nodes of the empty subtrees of each level
+	sorted   []int      // the indices of the leaves, sorted by value
+}
+
+// NonMembershipProof proves that a value isn't in the tree, with the merkle proof
+// of the low leaf, the leaf whose value is the greatest value lower than it
+type NonMembershipProof struct {
+	Value    *big.Int
+	LowLeaf  Leaf
+	Root     *big.Int
+	Path     []int // 0: left, 1: right
+	Siblings []*big.Int
+}
+
+// NewIndexedMT returns an indexed merkle tree of depth `depth`, holding at most 2^depth - 1 values
+func NewIndexedMT(hashFunc func([]*big.Int) (*big.Int, error), depth int) (*IndexedMT, error) {
+	if depth < 1 || depth > 32 {
+		return nil, fmt.Errorf("invalid depth %d", depth)
+	}
+	imt := &IndexedMT{
+		Depth:    depth,
+		Nodes:    make([][]*big.Int, depth+1),
+		HashFunc: hashFunc,
+		zeros:    []*big.Int{big.NewInt(0)},
+	}
+	for i := 0; i < depth; i++ {
+		zero, err := hashFunc([]*big.Int{imt.zeros[i], imt.zeros[i]})
+		if err != nil {
+			return nil, err
+		}
+		imt.zeros = append(imt.zeros, zero)
+	}
+	if err := imt.appendLeaf(Leaf{Value: big.NewInt(0), NextIndex: 0, NextValue: big.NewInt(0)}); err != nil {
+		return nil, err
+	}
+	return imt, nil
+}
+
+// Size returns the number of values in the tree, without the first leaf
+func (imt *IndexedMT) Size() int {
+	return len(imt.Leaves) - 1
+}
+
+// Root returns the root of the tree
+func (imt *IndexedMT) Root() *big.Int {
+	return imt.Nodes[imt.Depth][0]
+}
+
+// Has returns true if `value` is in the tree
+func (imt *IndexedMT) Has(value *big.Int) bool {
+	pos, found := imt.search(value)
+	return found && pos != 0
+}
+
+// Insert inserts a non-zero value into the tree
+func (imt *IndexedMT) Insert(value *big.Int) error {
+	if value == nil || value.Sign() <= 0 {
+		return fmt.Errorf("the value must be positive")
+	}
+	if len(imt.Leaves) == 1<<imt.Depth {
+		return fmt.Errorf("the tree is full")
+	}
+	pos, found := imt.search(value)
+	if found {
+		return fmt.Errorf("the value %v is already in the tree", value)
+	}
+
+	lowIdx := imt.sorted[pos-1]
+	low := imt.Leaves[lowIdx]
+	leaf := Leaf{Value: new(big.Int).Set(value), NextIndex: low.NextIndex, NextValue: low.NextValue}
+	idx := len(imt.Leaves)
+	if err := imt.appendLeaf(leaf); err != nil {
+		return err
+	}
+	low.NextIndex, low.NextValue = idx, leaf.Value
+	if err := imt.setLeaf(lowIdx, low); err != nil {
+		return err
+	}
+	imt.sorted = slices.Insert(imt.sorted, pos, idx)
+	return nil
+}
+
+// GenerateNonMembershipProof returns the proof that `value` isn't in the tree
+func (imt *IndexedMT) GenerateNonMembershipProof(value *big.Int) (NonMembershipProof, error) {
+	var proof NonMembershipProof
+	if value == nil || value.Sign() <= 0 {
+		return proof, fmt.Errorf("the value must be positive")
+	}
+	pos, found := imt.search(value)
+	if found {
+		return proof, fmt.Errorf("the value %v is in the tree", value)
+	}
+
+	lowIdx := imt.sorted[pos-1]
+	proof.Value = new(big.Int).Set(value)
+	proof.LowLeaf = imt.Leaves[lowIdx]
+	proof.Root = imt.Root()
+	index := lowIdx
+	for i := 0; i < imt.Depth; i++ {
+		proof.Path = append(proof.Path, index%2)
+		proof.Siblings = append(proof.Siblings, imt.node(i, index^1))
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyNonMembershipProof checks that a non-membership proof is valid
+func VerifyNonMembershipProof(hashFunc func([]*big.Int) (*big.Int, error), proof *NonMembershipProof) bool {
+	low := proof.LowLeaf
+	if proof.Value == nil || low.Value == nil || low.NextValue == nil || len(proof.Path) != len(proof.Siblings) {
+		return false
+	}
+	if low.Value.Cmp(proof.Value) >= 0 {
+		return false
+	}
+	if low.NextValue.Sign() != 0 && proof.Value.Cmp(low.NextValue) >= 0 {
+		return false
+	}
+
+	root, err := HashLeaf(hashFunc, low)
+	if err != nil {
+		return false
+	}
+	for i := range proof.Path {
+		if proof.Path[i] == 1 {
+			root, err = hashFunc([]*big.Int{proof.Siblings[i], root})
+		} else {
+			root, err = hashFunc([]*big.Int{root, proof.Siblings[i]})
+		}
+		if err != nil {
+			return false
+		}
+	}
+	return root.Cmp(proof.Root) == 0
+}
+
+// HashLeaf returns the hash of a leaf, hash(Value, NextIndex, NextValue)
+func HashLeaf(hashFunc func([]*big.Int) (*big.Int, error), leaf Leaf) (*big.Int, error) {
+	return hashFunc([]*big.Int{leaf.Value, big.NewInt(int64(leaf.NextIndex)), leaf.NextValue})
+}
+
+// search returns the position of `value` in the sorted leaves, or the position
+// at which it would be inserted, and true if it is in the tree
+func (imt *IndexedMT) search(value *big.Int) (int, bool) {
+	return slices.BinarySearchFunc(imt.sorted, value, func(idx int, v *big.Int) int {
+		return imt.Leaves[idx].Value.Cmp(v)
+	})
+}
+
+// node returns the node at `index` of the level `level`
+func (imt *IndexedMT) node(level, index int) *big.Int {
+	if index < len(imt.Nodes[level]) {
+		return imt.Nodes[level][index]
+	}
+	return imt.zeros[level]
+}
+
+// appendLeaf appends a leaf and its nodes to the tree
+func (imt *IndexedMT) appendLeaf(leaf Leaf) error {
+	idx := len(imt.Leaves)
+	imt.Leaves = append(imt.Leaves, leaf)
+	if idx == 0 {
+		imt.sorted = append(imt.sorted, 0)
+	}
+	index := idx
+	for i := 0; i <= imt.Depth; i++ {
+		if index == len(imt.Nodes[i]) {
+			imt.Nodes[i] = append(imt.Nodes[i], nil)
+		}
+		index /= 2
+	}
+	return imt.setLeaf(idx, leaf)
+}
+
+// setLeaf updates a leaf and the nodes of its path to the root
+func (imt *IndexedMT) setLeaf(idx int, leaf Leaf) error {
+	node, err := HashLeaf(imt.HashFunc, leaf)
+	if err != nil {
+		return err
+	}
+	imt.Leaves[idx] = leaf
+	index := idx
+	imt.Nodes[0][index] = node
+	for i := 0; i < imt.Depth; i++ {
+		if index%2 == 0 {
+			node, err = imt.HashFunc([]*big.Int{node, imt.node(i, index+1)})
+		} else {
+			node, err = imt.HashFunc([]*big.Int{imt.node(i, index-1), node})
+		}
+		if err != nil {
+			return err
+		}
+		index /= 2
+		imt.Nodes[i+1][index] = node
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package indexedMT
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/stretchr/testify/require"
+)
+
+// validateIMT checks that the linked list is sorted and that the nodes match the leaves
+func validateIMT(t *testing.T, imt *IndexedMT) {
+	empty, err := NewIndexedMT(imt.HashFunc, imt.Depth)
+	require.NoError(t, err)
+	idx, count := 0, 0
+	for {
+		leaf := imt.Leaves[idx]
+		if leaf.NextValue.Sign() == 0 {
+			break
+		}
+		require.Equal(t, 0, leaf.NextValue.Cmp(imt.Leaves[leaf.NextIndex].Value))
+		require.Equal(t, -1, leaf.Value.Cmp(leaf.NextValue))
+		idx = leaf.NextIndex
+		count++
+	}
+	require.Equal(t, imt.Size(), count)
+
+	// Recompute the root from all the leaves of the tree
+	level := []*big.Int{}
+	for i := 0; i < 1<<imt.Depth; i++ {
+		if i < len(imt.Leaves) {
+			node, err := HashLeaf(imt.HashFunc, imt.Leaves[i])
+			require.NoError(t, err)
+			level = append(level, node)
+		} else {
+			level = append(level, big.NewInt(0))
+		}
+	}
+	for len(level) > 1 {
+		next := []*big.Int{}
+		for i := 0; i < len(level); i += 2 {
+			node, err := imt.HashFunc([]*big.Int{level[i], level[i+1]})
+			require.NoError(t, err)
+			next = append(next, node)
+		}
+		level = next
+	}
+	require.Equal(t, level[0], imt.Root())
+	require.NotEqual(t, empty.Root(), imt.Root())
+}
+
+// TestIndexedMT checks insertions and non-membership proofs
+func TestIndexedMT(t *testing.T) {
+	imt, err := NewIndexedMT(poseidon.Hash, 4)
+	require.NoError(t, err)
+	require.Equal(t, 0, imt.Size())
+
+	values := []*big.Int{}
+	for i := 0; i < 15; i++ {
+		v := big.NewInt(rand.Int64N(1000) + 2)
+		if imt.Has(v) {
+			require.Error(t, imt.Insert(v))
+			continue
+		}
+		require.NoError(t, imt.Insert(v))
+		values = append(values, v)
+		validateIMT(t, imt)
+	}
+	for imt.Size() < 15 {
+		v := big.NewInt(rand.Int64N(1000) + 2)
+		if !imt.Has(v) {
+			require.NoError(t, imt.Insert(v))
+			values = append(values, v)
+		}
+	}
+	validateIMT(t, imt)
+	require.Error(t, imt.Insert(big.NewInt(5000)))
+	require.Error(t, imt.Insert(big.NewInt(0)))
+
+	// Values in the tree have no proof
+	for _, v := range values {
+		require.True(t, imt.Has(v))
+		_, err := imt.GenerateNonMembershipProof(v)
+		require.Error(t, err)
+	}
+
+	// Values out of the tree, lower, between and greater than the values
+	for _, v := range []*big.Int{big.NewInt(1), big.NewInt(500), big.NewInt(1001), big.NewInt(5000)} {
+		if imt.Has(v) {
+			continue
+		}
+		proof, err := imt.GenerateNonMembershipProof(v)
+		require.NoError(t, err)
+		require.Len(t, proof.Siblings, imt.Depth)
+		require.True(t, VerifyNonMembershipProof(imt.HashFunc, &proof))
+
+		// The proof doesn't hold for the values of the leaves
+		forged := proof
+		forged.Value = proof.LowLeaf.Value
+		require.False(t, VerifyNonMembershipProof(imt.HashFunc, &forged))
+		if proof.LowLeaf.NextValue.Sign() != 0 {
+			forged.Value = proof.LowLeaf.NextValue
+			require.False(t, VerifyNonMembershipProof(imt.HashFunc, &forged))
+			forged = proof
+			forged.LowLeaf.NextValue = big.NewInt(0)
+			require.False(t, VerifyNonMembershipProof(imt.HashFunc, &forged))
+		}
+	}
+}
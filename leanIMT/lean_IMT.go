@@ -1,11 +1,16 @@
 package leanIMT
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/big"
 )
 
+// CONTEXT_CHECK_INTERVAL is the number of nodes hashed between two context checks
+// inside a tree level
+const CONTEXT_CHECK_INTERVAL = 1024
+
 type LeanIMT struct {
 	Nodes    [][]*big.Int
 	HashFunc func([]*big.Int) (*big.Int, error)
@@ -13,6 +18,12 @@ type LeanIMT struct {
 
 // NewLeanIMT generates an instance of LeanIMT with provided leaves
 func NewLeanIMT(hashFunc func([]*big.Int) (*big.Int, error), leaves []*big.Int) (*LeanIMT, error) {
+	return NewLeanIMTContext(context.Background(), hashFunc, leaves)
+}
+
+// NewLeanIMTContext generates an instance of LeanIMT with provided leaves,
+// the insertion of the leaves is cancelled if `ctx` is done
+func NewLeanIMTContext(ctx context.Context, hashFunc func([]*big.Int) (*big.Int, error), leaves []*big.Int) (*LeanIMT, error) {
 	imt := &LeanIMT{
 		Nodes:    [][]*big.Int{},
 		HashFunc: hashFunc,
@@ -20,7 +31,7 @@ func NewLeanIMT(hashFunc func([]*big.Int) (*big.Int, error), leaves []*big.Int)
 
 	// Insert leaves
 	if len(leaves) != 0 {
-		err := imt.InsertMany(leaves)
+		err := imt.InsertManyContext(ctx, leaves)
 		if err != nil {
 			return nil, err
 		}
@@ -101,9 +112,21 @@ func (imt *LeanIMT) Insert(leaf *big.Int) error {
 
 // InsertMany adds a batch of leaves to the tree
 func (imt *LeanIMT) InsertMany(leaves []*big.Int) error {
+	return imt.InsertManyContext(context.Background(), leaves)
+}
+
+// InsertManyContext adds a batch of leaves to the tree, checking `ctx` between tree levels
+// (and every CONTEXT_CHECK_INTERVAL hashes inside a level).
+// The tree is left unchanged if `ctx` is done before the insertion completes
+func (imt *LeanIMT) InsertManyContext(ctx context.Context, leaves []*big.Int) error {
 	if len(leaves) == 0 {
 		return fmt.Errorf("invalid leaves")
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	jr := imt.snapshot(ctx)
+	start := imt.Size()
 
 	// Add more levels to accommodate all the leaves
 	for int(math.Ceil(math.Log2(float64(imt.Size()+len(leaves))))) > imt.Depth() {
@@ -113,9 +136,15 @@ func (imt *LeanIMT) InsertMany(leaves []*big.Int) error {
 	// Add all the leaves
 	imt.Nodes[0] = append(imt.Nodes[0], leaves...)
 
-	// Update parents hash
+	// Update parents hash, from the first pair holding a new node
 	for i := 0; i < imt.Depth(); i++ {
-		for j := 0; j < len(imt.Nodes[i]); j += 2 {
+		for j := (start >> i) &^ 1; j < len(imt.Nodes[i]); j += 2 {
+			if j%CONTEXT_CHECK_INTERVAL == 0 {
+				if err := ctx.Err(); err != nil {
+					jr.restore(imt)
+					return err
+				}
+			}
 			parentIdx := j / 2
 
 			// Calculate parents hash
@@ -127,6 +156,7 @@ func (imt *LeanIMT) InsertMany(leaves []*big.Int) error {
 				val, err = imt.HashFunc([]*big.Int{imt.Nodes[i][j], imt.Nodes[i][j+1]})
 				if err != nil {
 					fmt.Println("hash err:", err)
+					jr.restore(imt)
 					return err
 				}
 			}
@@ -135,7 +165,7 @@ func (imt *LeanIMT) InsertMany(leaves []*big.Int) error {
 			if parentIdx >= len(imt.Nodes[i+1]) {
 				imt.Nodes[i+1] = append(imt.Nodes[i+1], val)
 			} else {
-				imt.Nodes[i+1][parentIdx] = val
+				jr.set(imt, i+1, parentIdx, val)
 			}
 		}
 	}
@@ -143,6 +173,55 @@ func (imt *LeanIMT) InsertMany(leaves []*big.Int) error {
 	return nil
 }
 
+// journal records the changes of a batch operation so that they can be undone:
+// the size of each level before the operation, and the nodes it overwrote
+type journal struct {
+	sizes       []int
+	overwritten []overwrittenNode
+}
+
+type overwrittenNode struct {
+	level, index int
+	value        *big.Int
+}
+
+// snapshot returns a journal of the tree if `ctx` can be cancelled, else nil
+// as the operation can't be interrupted. Its cost is the number of changed nodes
+func (imt *LeanIMT) snapshot(ctx context.Context) *journal {
+	if ctx.Done() == nil {
+		return nil
+	}
+	jr := &journal{sizes: make([]int, len(imt.Nodes))}
+	for i := range imt.Nodes {
+		jr.sizes[i] = len(imt.Nodes[i])
+	}
+	return jr
+}
+
+// set overwrites a node of the tree, recording its value if it existed before the operation
+func (jr *journal) set(imt *LeanIMT, level, index int, value *big.Int) {
+	if jr != nil && level < len(jr.sizes) && index < jr.sizes[level] {
+		jr.overwritten = append(jr.overwritten, overwrittenNode{level, index, imt.Nodes[level][index]})
+	}
+	imt.Nodes[level][index] = value
+}
+
+// restore undoes the changes of the operation: the added levels and nodes are dropped,
+// and the overwritten nodes restored in reverse order
+func (jr *journal) restore(imt *LeanIMT) {
+	if jr == nil {
+		return
+	}
+	imt.Nodes = imt.Nodes[:len(jr.sizes)]
+	for i, size := range jr.sizes {
+		imt.Nodes[i] = imt.Nodes[i][:size]
+	}
+	for i := len(jr.overwritten) - 1; i >= 0; i-- {
+		n := jr.overwritten[i]
+		imt.Nodes[n.level][n.index] = n.value
+	}
+}
+
 // Update helps to change value of a specific leaf in the tree
 func (imt *LeanIMT) Update(newVal *big.Int, idx int) error {
 
@@ -183,6 +262,13 @@ func (imt *LeanIMT) Update(newVal *big.Int, idx int) error {
 
 // UpdateMany helps to update values of a batch of leaves according indices
 func (imt *LeanIMT) UpdateMany(leaves []*big.Int, indices []int) error {
+	return imt.UpdateManyContext(context.Background(), leaves, indices)
+}
+
+// UpdateManyContext helps to update values of a batch of leaves according indices,
+// checking `ctx` between tree levels.
+// The tree is left unchanged if `ctx` is done before the update completes
+func (imt *LeanIMT) UpdateManyContext(ctx context.Context, leaves []*big.Int, indices []int) error {
 
 	// Check that the updated params are valid
 	if len(leaves) != len(indices) {
@@ -197,16 +283,24 @@ func (imt *LeanIMT) UpdateMany(leaves []*big.Int, indices []int) error {
 		}
 		modifiedIndicesMap[indices[i]] = true
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	jr := imt.snapshot(ctx)
 
 	// Update leaves
 	modifiedIndicesMap = make(map[int]bool)
 	for i := 0; i < len(indices); i++ {
-		imt.Nodes[0][indices[i]] = leaves[i]
+		jr.set(imt, 0, indices[i], leaves[i])
 		modifiedIndicesMap[indices[i]/2] = true
 	}
 
 	// Update inner Nodes
 	for i := 1; i <= imt.Depth(); i++ {
+		if err := ctx.Err(); err != nil {
+			jr.restore(imt)
+			return err
+		}
 		newModifiedIndicesMap := make(map[int]bool)
 		for key := range modifiedIndicesMap {
 			leftNode := imt.Nodes[i-1][key*2]
@@ -219,10 +313,11 @@ func (imt *LeanIMT) UpdateMany(leaves []*big.Int, indices []int) error {
 				val, err = imt.HashFunc([]*big.Int{leftNode, rightNode})
 				if err != nil {
 					fmt.Println("hash err:", err)
+					jr.restore(imt)
 					return err
 				}
 			}
-			imt.Nodes[i][key] = val
+			jr.set(imt, i, key, val)
 			newModifiedIndicesMap[key/2] = true
 		}
 		modifiedIndicesMap = newModifiedIndicesMap
@@ -1,9 +1,12 @@
 package leanIMT
 
 import (
+	"context"
+	"errors"
 	"math/big"
 	"math/rand/v2"
 	"testing"
+	"time"
 
 	"github.com/iden3/go-iden3-crypto/poseidon"
 	"github.com/stretchr/testify/require"
@@ -186,6 +189,88 @@ func TestVerifyProof(t *testing.T) {
 	require.True(t, imt.VerifyProof(&merkleProof))
 }
 
+// TestInsertManyContext checks that a batch insertion is cancelled promptly
+// and leaves the tree unchanged
+func TestInsertManyContext(t *testing.T) {
+	imt, err := NewLeanIMT(poseidon.Hash, randomBigIntArray(5))
+	require.NoError(t, err)
+	root := imt.Root()
+
+	// Already cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = imt.InsertManyContext(ctx, randomBigIntArray(5))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 5, imt.Size())
+
+	// Cancelled while hashing a large batch
+	n := 1 << 20
+	leaves := make([]*big.Int, n)
+	for i := range leaves {
+		leaves[i] = big.NewInt(int64(i))
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = imt.InsertManyContext(ctx, leaves)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, 5, imt.Size())
+	require.Equal(t, root, imt.Root())
+	validateIMT(t, imt)
+
+	// Not cancelled
+	err = imt.InsertManyContext(context.Background(), randomBigIntArray(5))
+	require.NoError(t, err)
+	require.Equal(t, 10, imt.Size())
+	validateIMT(t, imt)
+}
+
+// TestUpdateManyContext checks that a cancelled batch update leaves the tree unchanged
+func TestUpdateManyContext(t *testing.T) {
+	imt, err := NewLeanIMT(poseidon.Hash, randomBigIntArray(5))
+	require.NoError(t, err)
+	leaves := append([]*big.Int{}, imt.Nodes[0]...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = imt.UpdateManyContext(ctx, randomBigIntArray(2), []int{0, 3})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, leaves, imt.Nodes[0])
+	validateIMT(t, imt)
+}
+
+// TestBatchRestore checks that a batch interrupted after overwriting nodes
+// restores them, and drops the nodes and levels it added
+func TestBatchRestore(t *testing.T) {
+	imt, err := NewLeanIMT(poseidon.Hash, randomBigIntArray(5))
+	require.NoError(t, err)
+	nodes := make([][]*big.Int, len(imt.Nodes))
+	for i := range imt.Nodes {
+		nodes[i] = append([]*big.Int{}, imt.Nodes[i]...)
+	}
+
+	// The hash fails once some parents were overwritten
+	calls := 0
+	imt.HashFunc = func(in []*big.Int) (*big.Int, error) {
+		if calls++; calls > 3 {
+			return nil, errors.New("hash failure")
+		}
+		return poseidon.Hash(in)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.Error(t, imt.InsertManyContext(ctx, randomBigIntArray(6)))
+	require.Equal(t, nodes, imt.Nodes)
+
+	calls = 0
+	require.Error(t, imt.UpdateManyContext(ctx, randomBigIntArray(3), []int{0, 2, 4}))
+	require.Equal(t, nodes, imt.Nodes)
+
+	imt.HashFunc = poseidon.Hash
+	validateIMT(t, imt)
+}
+
 // validateIMT validates the integrity of the LeanIMT by ensuring that each parent node
 // is correctly computed from its child Nodes using the Poseidon hash function.
 func validateIMT(t *testing.T, imt *LeanIMT) {
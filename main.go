@@ -1,8 +1,67 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark/logger"
+)
+
+const usage = `Usage: semaphore <command> [arguments]
+
+Commands:
+  identity new      generate a new identity
+  identity recover  recover an identity from its mnemonic
+  identity export   print the identity commitment of an identity
+  identity encrypt  encrypt an identity into a keystore with a passphrase
+  identity decrypt  decrypt the identity of a keystore
+  identity rotate   replace an identity by a new one, in a group too
+  group create      create a new group file
+  group add         add an identity commitment to a group
+  group remove      remove an identity commitment from a group
+  group root        print the merkle root of a group
+  group proof       print the merkle proof of a group member
+  setup             setup the semaphore circuit and write its keys to disk
+  prove             generate a semaphore proof
+  verify            verify a semaphore proof
+
+Run 'semaphore <command> -h' for more details about a command.
+`
 
 func main() {
-	fmt.Println("Hello, World!")
-	fmt.Println("Want the main flow? Check the ./semaphore/semaphore_test.go file for more details :D")
+	// gnark logs to stdout by default, where the commands print their output
+	logger.SetOutput(os.Stderr)
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches the command line arguments to the matching subcommand,
+// the usage is printed to `stderr` on errors
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("missing command")
+	}
+
+	switch args[0] {
+	case "identity":
+		return runIdentity(args[1:], stdout)
+	case "group":
+		return runGroup(args[1:], stdout)
+	case "setup":
+		return runSetup(args[1:], stdout)
+	case "prove":
+		return runProve(args[1:], stdout)
+	case "verify":
+		return runVerify(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
 }
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCommandLineFlow runs the whole flow through the command line tool:
+// identities, group management, setup, proving and verifying
+func TestCommandLineFlow(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	var stdout bytes.Buffer
+
+	// Create identities
+	n := 3
+	identities := []string{}
+	for i := 0; i < n; i++ {
+		p := path("identity" + string(rune('0'+i)) + ".json")
+		require.NoError(t, run([]string{"identity", "new", "-out", p}, &stdout, io.Discard))
+		identities = append(identities, p)
+	}
+
+	// Mnemonic identities can be recovered, the passphrases are read from the environment
+	t.Setenv(PASSPHRASE_ENV, "pass")
+	mnemonicPath := path("mnemonic.json")
+	require.NoError(t, run([]string{"identity", "new", "-mnemonic", "-with-passphrase", "-out", mnemonicPath}, &stdout, io.Discard))
+	var f IdentityFile
+	require.NoError(t, readJSON(mnemonicPath, &f))
+	stdout.Reset()
+	t.Setenv(MNEMONIC_ENV, f.Mnemonic)
+	require.NoError(t, run([]string{"identity", "recover", "-with-passphrase"}, &stdout, io.Discard))
+	require.Contains(t, stdout.String(), f.Commitment)
+	stdout.Reset()
+	require.NoError(t, run([]string{"identity", "recover"}, &stdout, io.Discard))
+	require.NotContains(t, stdout.String(), f.Commitment)
+	require.Error(t, run([]string{"identity", "recover", "-mnemonic", f.Mnemonic}, &stdout, io.Discard))
+	require.Error(t, run([]string{"identity", "recover", "-passphrase", "pass"}, &stdout, io.Discard))
+	t.Setenv(MNEMONIC_ENV, "abandon abandon")
+	require.Error(t, run([]string{"identity", "recover"}, &stdout, io.Discard))
+
+	// Export a commitment
+	stdout.Reset()
+	require.NoError(t, run([]string{"identity", "export", "-identity", identities[0]}, &stdout, io.Discard))
+	require.Contains(t, stdout.String(), "commitment")
+
+	// Create the group and add members
+	group := path("group.json")
+	require.NoError(t, run([]string{"group", "create", "-group", group}, &stdout, io.Discard))
+	for _, id := range identities {
+		require.NoError(t, run([]string{"group", "add", "-group", group, "-identity", id}, &stdout, io.Discard))
+	}
+	require.Error(t, run([]string{"group", "add", "-group", group, "-identity", identities[0]}, &stdout, io.Discard))
+
+	// Remove a member
+	require.NoError(t, run([]string{"group", "remove", "-group", group, "-identity", identities[2]}, &stdout, io.Discard))
+	require.Error(t, run([]string{"group", "remove", "-group", group, "-identity", identities[2]}, &stdout, io.Discard))
+
+	// Encrypt, decrypt and rotate an identity
+	keystore := path("keystore.json")
+	require.NoError(t, run([]string{"identity", "encrypt", "-identity", identities[0], "-light", "-out", keystore}, &stdout, io.Discard))
+	t.Setenv(PASSPHRASE_ENV, "wrong")
+	require.Error(t, run([]string{"identity", "decrypt", "-keystore", keystore}, &stdout, io.Discard))
+	t.Setenv(PASSPHRASE_ENV, "pass")
+	decrypted := path("decrypted.json")
+	require.NoError(t, run([]string{"identity", "decrypt", "-keystore", keystore, "-out", decrypted}, &stdout, io.Discard))
+	var old, dec IdentityFile
+	require.NoError(t, readJSON(identities[0], &old))
+	require.NoError(t, readJSON(decrypted, &dec))
+	require.Equal(t, old.Secret, dec.Secret)
+	rotated := path("rotated.json")
+	require.NoError(t, run([]string{"identity", "rotate", "-identity", identities[0], "-group", group, "-out", rotated}, &stdout, io.Discard))
+	require.Error(t, run([]string{"group", "proof", "-group", group, "-identity", identities[0]}, &stdout, io.Discard))
+	require.NoError(t, run([]string{"group", "proof", "-group", group, "-identity", rotated}, &stdout, io.Discard))
+	identities[0] = rotated
+
+	// Group root and merkle proof
+	stdout.Reset()
+	require.NoError(t, run([]string{"group", "root", "-group", group}, &stdout, io.Discard))
+	require.Contains(t, stdout.String(), "root")
+	stdout.Reset()
+	require.NoError(t, run([]string{"group", "proof", "-group", group, "-index", "1"}, &stdout, io.Discard))
+	require.Contains(t, stdout.String(), "siblings")
+
+	// Setup, prove and verify
+	keys := path("keys")
+	require.NoError(t, run([]string{"setup", "-keys", keys}, &stdout, io.Discard))
+	proof := path("proof.json")
+	require.NoError(t, run([]string{
+		"prove", "-keys", keys, "-identity", identities[1], "-group", group,
+		"-message", "42", "-scope", "2024", "-out", proof,
+	}, &stdout, io.Discard))
+	stdout.Reset()
+	require.NoError(t, run([]string{"verify", "-keys", keys, "-proof", proof, "-group", group}, &stdout, io.Discard))
+	require.Contains(t, stdout.String(), "proof is valid")
+
+	// Proofs bound to a domain are only accepted by the verifiers of the domain
+	domain, otherDomain := path("domain.json"), path("other_domain.json")
+	require.NoError(t, writeJSON(domain, nil, semaphore.Domain{AppID: "poll", ChainID: 1}))
+	require.NoError(t, writeJSON(otherDomain, nil, semaphore.Domain{AppID: "poll", ChainID: 10}))
+	bound := path("bound_proof.json")
+	require.NoError(t, run([]string{
+		"prove", "-keys", keys, "-identity", identities[1], "-group", group,
+		"-message", "42", "-scope", "2024", "-domain", domain, "-out", bound,
+	}, &stdout, io.Discard))
+	require.NoError(t, run([]string{"verify", "-keys", keys, "-proof", bound, "-domain", domain}, &stdout, io.Discard))
+	require.NoError(t, run([]string{"verify", "-keys", keys, "-proof", bound}, &stdout, io.Discard))
+	require.ErrorIs(t, run([]string{"verify", "-keys", keys, "-proof", bound, "-domain", otherDomain}, &stdout, io.Discard), semaphore.ErrWrongDomain)
+	require.ErrorIs(t, run([]string{"verify", "-keys", keys, "-proof", proof, "-domain", domain}, &stdout, io.Discard), semaphore.ErrWrongDomain)
+	var boundFile ProofFile
+	require.NoError(t, readJSON(bound, &boundFile))
+	boundFile.Domain = nil
+	require.NoError(t, writeJSON(bound, nil, boundFile))
+	require.Error(t, run([]string{"verify", "-keys", keys, "-proof", bound}, &stdout, io.Discard))
+
+	// Nullifiers per epoch, the epoch is carried by the proof file
+	epochKeys := path("epoch_keys")
+	require.NoError(t, run([]string{"setup", "-keys", epochKeys, "-nullifier", "per_epoch"}, &stdout, io.Discard))
+	epochProof := path("epoch_proof.json")
+	require.Error(t, run([]string{
+		"prove", "-keys", epochKeys, "-identity", identities[1], "-group", group,
+		"-message", "42", "-scope", "2024", "-nullifier", "per_epoch", "-out", epochProof,
+	}, &stdout, io.Discard))
+	require.NoError(t, run([]string{
+		"prove", "-keys", epochKeys, "-identity", identities[1], "-group", group,
+		"-message", "42", "-scope", "2024", "-nullifier", "per_epoch", "-nullifier-input", "7", "-out", epochProof,
+	}, &stdout, io.Discard))
+	require.NoError(t, run([]string{"verify", "-keys", epochKeys, "-proof", epochProof}, &stdout, io.Discard))
+	require.Error(t, run([]string{"verify", "-keys", keys, "-proof", epochProof}, &stdout, io.Discard))
+	var epochFile ProofFile
+	require.NoError(t, readJSON(epochProof, &epochFile))
+	require.Equal(t, "per_epoch", epochFile.NullifierMode)
+	epochFile.NullifierInput = "8"
+	require.NoError(t, writeJSON(epochProof, nil, epochFile))
+	require.Error(t, run([]string{"verify", "-keys", epochKeys, "-proof", epochProof}, &stdout, io.Discard))
+
+	// Timestamped proofs, checked against the clock of the verifier
+	timestampKeys := path("timestamp_keys")
+	require.Error(t, run([]string{"setup", "-keys", timestampKeys, "-timestamp", "-nullifier", "per_app"}, &stdout, io.Discard))
+	require.NoError(t, run([]string{"setup", "-keys", timestampKeys, "-timestamp"}, &stdout, io.Discard))
+	timestamped := path("timestamped_proof.json")
+	require.NoError(t, run([]string{
+		"prove", "-keys", timestampKeys, "-identity", identities[1], "-group", group,
+		"-message", "42", "-scope", "2024", "-timestamp", "-out", timestamped,
+	}, &stdout, io.Discard))
+	require.NoError(t, run([]string{"verify", "-keys", timestampKeys, "-proof", timestamped, "-max-clock-skew", "1m"}, &stdout, io.Discard))
+	require.Error(t, run([]string{"verify", "-keys", keys, "-proof", proof, "-max-clock-skew", "1m"}, &stdout, io.Discard))
+	var timestampedFile ProofFile
+	require.NoError(t, readJSON(timestamped, &timestampedFile))
+	timestampedFile.Timestamp = "1000"
+	require.NoError(t, writeJSON(timestamped, nil, timestampedFile))
+	require.Error(t, run([]string{"verify", "-keys", timestampKeys, "-proof", timestamped, "-max-clock-skew", "1m"}, &stdout, io.Discard))
+	require.Error(t, run([]string{"verify", "-keys", timestampKeys, "-proof", timestamped}, &stdout, io.Discard))
+
+	// Without -out, the proof is the only output of the binary on stdout, gnark logs to stderr
+	exe := path("semaphore")
+	out, err := exec.Command("go", "build", "-o", exe, ".").CombinedOutput()
+	require.NoError(t, err, string(out))
+	var stderr bytes.Buffer
+	cmd := exec.Command(exe, "prove", "-keys", keys, "-identity", identities[1], "-group", group, "-message", "43", "-scope", "2024")
+	cmd.Stderr = &stderr
+	out, err = cmd.Output()
+	require.NoError(t, err, stderr.String())
+	var printed ProofFile
+	require.NoError(t, json.Unmarshal(out, &printed))
+	_, sProof, err := decodeProof(printed)
+	require.NoError(t, err)
+	require.Equal(t, int64(43), sProof.Message.Int64())
+
+	// Without the environment variable, the passphrase is read from stdin
+	cmd = exec.Command(exe, "identity", "decrypt", "-keystore", keystore)
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, PASSPHRASE_ENV+"=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	cmd.Stdin = strings.NewReader("pass\n")
+	out, err = cmd.Output()
+	require.NoError(t, err)
+	var printedID IdentityFile
+	require.NoError(t, json.Unmarshal(out, &printedID))
+	require.Equal(t, old.Secret, printedID.Secret)
+
+	// The mnemonic and its passphrase are read from successive lines of stdin
+	cmd = exec.Command(exe, "identity", "recover", "-with-passphrase")
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, PASSPHRASE_ENV+"=") && !strings.HasPrefix(env, MNEMONIC_ENV+"=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	cmd.Stdin = strings.NewReader(f.Mnemonic + "\npass\n")
+	out, err = cmd.Output()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(out, &printedID))
+	require.Equal(t, f.Commitment, printedID.Commitment)
+
+	// Removed members can't prove
+	require.Error(t, run([]string{
+		"prove", "-keys", keys, "-identity", identities[2], "-group", group,
+		"-message", "42", "-scope", "2024",
+	}, &stdout, io.Discard))
+
+	// The proof doesn't match the group once it changes
+	require.NoError(t, run([]string{"group", "remove", "-group", group, "-identity", identities[0]}, &stdout, io.Discard))
+	require.Error(t, run([]string{"verify", "-keys", keys, "-proof", proof, "-group", group}, &stdout, io.Discard))
+}
+
+// TestUsage checks that the usage is printed to stdout on request, and to stderr on errors
+func TestUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, run([]string{"help"}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "Usage")
+	require.Empty(t, stderr.String())
+
+	stdout.Reset()
+	require.Error(t, run(nil, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "Usage")
+	stderr.Reset()
+	require.Error(t, run([]string{"unknown"}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "Usage")
+	require.Empty(t, stdout.String())
+}
@@ -0,0 +1,120 @@
+package onchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// assembler builds EVM bytecode, jumps refer to labels resolved by bytes()
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	refs   map[int]string // offset of a PUSH2 operand -> label
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: make(map[string]int), refs: make(map[int]string)}
+}
+
+// op appends opcodes
+func (a *assembler) op(ops ...vm.OpCode) *assembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+// push appends the shortest PUSH of `v`
+func (a *assembler) push(v *big.Int) *assembler {
+	b := v.Bytes()
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	if len(b) > 32 {
+		panic("push of more than 32 bytes")
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(b)-1))
+	a.code = append(a.code, b...)
+	return a
+}
+
+// pushInt appends the shortest PUSH of `v`
+func (a *assembler) pushInt(v uint64) *assembler {
+	return a.push(new(big.Int).SetUint64(v))
+}
+
+// pushBytes appends a PUSH of `b` as is
+func (a *assembler) pushBytes(b []byte) *assembler {
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(b)-1))
+	a.code = append(a.code, b...)
+	return a
+}
+
+// pushLabel appends a PUSH2 of the offset of a label
+func (a *assembler) pushLabel(name string) *assembler {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.refs[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+// label marks the current offset with a JUMPDEST
+func (a *assembler) label(name string) *assembler {
+	a.labels[name] = len(a.code)
+	return a.op(vm.JUMPDEST)
+}
+
+// jump appends an unconditional jump to a label
+func (a *assembler) jump(name string) *assembler {
+	return a.pushLabel(name).op(vm.JUMP)
+}
+
+// jumpi appends a jump to a label taken if the top of the stack is not zero
+func (a *assembler) jumpi(name string) *assembler {
+	return a.pushLabel(name).op(vm.JUMPI)
+}
+
+// mstore stores `v` at memory `offset`
+func (a *assembler) mstore(offset uint64, v *big.Int) *assembler {
+	return a.push(v).pushInt(offset).op(vm.MSTORE)
+}
+
+// revertWith reverts with the 4 bytes selector of a custom error
+func (a *assembler) revertWith(selector []byte) *assembler {
+	return a.push(selectorWord(selector)).pushInt(0).op(vm.MSTORE).
+		pushInt(4).pushInt(0).op(vm.REVERT)
+}
+
+// bytes resolves the labels and returns the bytecode
+func (a *assembler) bytes() ([]byte, error) {
+	code := append([]byte{}, a.code...)
+	for offset, name := range a.refs {
+		dest, ok := a.labels[name]
+		if !ok {
+			return nil, fmt.Errorf("undefined label %q", name)
+		}
+		if dest > 0xffff {
+			return nil, fmt.Errorf("label %q is out of range", name)
+		}
+		code[offset], code[offset+1] = byte(dest>>8), byte(dest)
+	}
+	return code, nil
+}
+
+// deployCode returns the creation code returning `runtime` as the contract code
+func deployCode(runtime []byte) []byte {
+	// PUSH2 len, DUP1, PUSH1 init_len, PUSH1 0, CODECOPY, PUSH1 0, RETURN
+	init := []byte{
+		byte(vm.PUSH2), byte(len(runtime) >> 8), byte(len(runtime)),
+		byte(vm.DUP1),
+		byte(vm.PUSH1), 0, // patched below
+		byte(vm.PUSH1), 0,
+		byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	init[5] = byte(len(init))
+	return append(init, runtime...)
+}
@@ -0,0 +1,228 @@
+package onchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ROOT_DURATION is the time during which the group contract still accepts
+// the proofs of a root after it was replaced
+const ROOT_DURATION = time.Hour
+
+// Errors reverted by the group contract, mapped to the errors of the semaphore package
+var revertErrors = map[string]error{
+	"Semaphore__CallerIsNotTheGroupAdmin":          fmt.Errorf("the caller is not the group admin"),
+	"Semaphore__InvalidIdentityCommitment":         fmt.Errorf("invalid identity commitment"),
+	"Semaphore__MerkleTreeIsFull":                  fmt.Errorf("the group is full"),
+	"Semaphore__MerkleTreeRootIsNotPartOfTheGroup": semaphore.ErrInvalidMerkleRoot,
+	"Semaphore__MerkleTreeRootIsExpired":           semaphore.ErrInvalidMerkleRoot,
+	"Semaphore__YouAreUsingTheSameNullifierTwice":  semaphore.ErrNullifierUsed,
+	"Semaphore__InvalidProof":                      semaphore.ErrInvalidProof,
+}
+
+// Chain is an in-memory EVM chain running a verifier and a group contract.
+// The admin account deploys the contracts and adds the members of the group
+type Chain struct {
+	backend *simulated.Backend
+	client  simulated.Client
+	key     *ecdsa.PrivateKey
+	auth    *bind.TransactOpts
+
+	verifier common.Address
+	group    common.Address
+	contract *bind.BoundContract
+}
+
+// NewChain starts a simulated chain and deploys the verifier of `vk` and a group contract
+func NewChain(vk groth16.VerifyingKey) (*Chain, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	admin := crypto.PubkeyToAddress(key.PublicKey)
+	balance := new(big.Int).Lsh(big.NewInt(1), 100)
+	backend := simulated.NewBackend(types.GenesisAlloc{admin: {Balance: balance}})
+	c := &Chain{backend: backend, client: backend.Client(), key: key}
+	if err := c.deploy(vk); err != nil {
+		backend.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Chain) deploy(vk groth16.VerifyingKey) error {
+	chainID, err := c.client.ChainID(context.Background())
+	if err != nil {
+		return err
+	}
+	c.auth, err = bind.NewKeyedTransactorWithChainID(c.key, chainID)
+	if err != nil {
+		return err
+	}
+	verifierCode, err := VerifierCode(vk)
+	if err != nil {
+		return err
+	}
+	c.verifier, err = c.deployCode(verifierABI, verifierCode)
+	if err != nil {
+		return fmt.Errorf("failed to deploy the verifier: %w", err)
+	}
+
+	groupCode, err := GroupCode(c.verifier, c.auth.From, ROOT_DURATION)
+	if err != nil {
+		return err
+	}
+	c.group, err = c.deployCode(groupABI, groupCode)
+	if err != nil {
+		return fmt.Errorf("failed to deploy the group: %w", err)
+	}
+	c.contract = bind.NewBoundContract(c.group, groupABI, c.client, c.client, c.client)
+	return nil
+}
+
+// deployCode deploys a contract with runtime code `code`
+func (c *Chain) deployCode(contract abi.ABI, code []byte) (common.Address, error) {
+	addr, tx, _, err := bind.DeployContract(c.auth, contract, deployCode(code), c.client)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if err := c.mine(tx); err != nil {
+		return common.Address{}, err
+	}
+	return addr, nil
+}
+
+// Close stops the chain
+func (c *Chain) Close() error {
+	return c.backend.Close()
+}
+
+// Client returns the RPC client of the chain
+func (c *Chain) Client() simulated.Client {
+	return c.client
+}
+
+// Verifier returns the address of the verifier contract
+func (c *Chain) Verifier() common.Address {
+	return c.verifier
+}
+
+// Group returns the address of the group contract
+func (c *Chain) Group() common.Address {
+	return c.group
+}
+
+// AddMember adds an identity commitment to the group contract, which computes the new root
+func (c *Chain) AddMember(ctx context.Context, idc *big.Int) error {
+	return c.transact(ctx, "addMember", idc)
+}
+
+// ValidateProof submits a semaphore proof to the group contract, which marks its
+// nullifier as used. Rejected proofs return the matching error of the semaphore package
+func (c *Chain) ValidateProof(ctx context.Context, proof *groth16_bn254.Proof, sProof semaphore.SemaphoreProof) error {
+	if proof == nil {
+		return fmt.Errorf("missing proof")
+	}
+	packed := semaphore.PackPoints(proof)
+	var points [8]*big.Int
+	for i := range packed {
+		points[i], _ = new(big.Int).SetString(packed[i], 10)
+	}
+	return c.transact(ctx, "validateProof", sProof.MerkleRoot, sProof.Nullifier, sProof.Message, sProof.Scope, points)
+}
+
+// Root returns the root of the group stored by the contract
+func (c *Chain) Root(ctx context.Context) (*big.Int, error) {
+	return c.callUint(ctx, "getMerkleTreeRoot")
+}
+
+// Size returns the size of the group stored by the contract
+func (c *Chain) Size(ctx context.Context) (int, error) {
+	size, err := c.callUint(ctx, "getMerkleTreeSize")
+	if err != nil {
+		return 0, err
+	}
+	return int(size.Int64()), nil
+}
+
+// IsNullifierUsed returns true if the nullifier has been used by a validated proof
+func (c *Chain) IsNullifierUsed(ctx context.Context, nullifier *big.Int) (bool, error) {
+	res := []interface{}{}
+	if err := c.contract.Call(&bind.CallOpts{Context: ctx}, &res, "isNullifierUsed", nullifier); err != nil {
+		return false, err
+	}
+	return res[0].(bool), nil
+}
+
+func (c *Chain) callUint(ctx context.Context, method string) (*big.Int, error) {
+	res := []interface{}{}
+	if err := c.contract.Call(&bind.CallOpts{Context: ctx}, &res, method); err != nil {
+		return nil, err
+	}
+	return res[0].(*big.Int), nil
+}
+
+// transact sends a transaction to the group contract and mines it
+func (c *Chain) transact(ctx context.Context, method string, params ...interface{}) error {
+	opts := *c.auth
+	opts.Context = ctx
+	tx, err := c.contract.Transact(&opts, method, params...)
+	if err != nil {
+		return revertError(err)
+	}
+	return c.mine(tx)
+}
+
+// mine commits a block and checks the receipt of `tx`
+func (c *Chain) mine(tx *types.Transaction) error {
+	c.backend.Commit()
+	receipt, err := c.client.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s failed", tx.Hash())
+	}
+	return nil
+}
+
+// revertError maps the custom error reverted by the group contract, found
+// while estimating the gas of a transaction, to its semaphore error
+func revertError(err error) error {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	hex, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decodeErr := hexutil.Decode(hex)
+	if decodeErr != nil || len(data) < 4 {
+		return err
+	}
+	for name, e := range groupABI.Errors {
+		if id := e.ID; string(id[:4]) == string(data[:4]) {
+			if mapped, ok := revertErrors[name]; ok {
+				return fmt.Errorf("the group contract reverted with %s: %w", name, mapped)
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package onchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChain deploys the contracts on a simulated chain, adds members, syncs a listener
+// from the MemberAdded events and validates semaphore proofs on chain, at the current
+// root or at a previous root until it expires
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	ccs, pk, vk, err := semaphore.SetupCircuit()
+	require.NoError(t, err)
+	c, err := NewChain(vk)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// Add members, the contract computes the roots of the group
+	n := 5
+	secrets := []*big.Int{}
+	imt, err := leanIMT.NewLeanIMT(semaphore.MimcHash, []*big.Int{})
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		id, err := identity.New()
+		require.NoError(t, err)
+		idc := id.Commitment()
+		require.NoError(t, c.AddMember(ctx, idc))
+		secrets = append(secrets, id.Secret())
+
+		require.NoError(t, imt.Insert(idc))
+		root, err := c.Root(ctx)
+		require.NoError(t, err)
+		require.Equal(t, imt.Root(), root)
+	}
+	require.ErrorContains(t, c.AddMember(ctx, big.NewInt(0)), "Semaphore__InvalidIdentityCommitment")
+	size, err := c.Size(ctx)
+	require.NoError(t, err)
+	require.Equal(t, n, size)
+
+	// Sync a listener
+	l := NewListener(c.Client(), c.Group())
+	added, err := l.Sync(ctx)
+	require.NoError(t, err)
+	require.Equal(t, n, added)
+	root, err := c.Root(ctx)
+	require.NoError(t, err)
+	require.Equal(t, root, l.Root())
+
+	// Generate a semaphore proof from the tree of the listener
+	merkleProof, err := l.GenerateMerkleProof(1)
+	require.NoError(t, err)
+	scope := big.NewInt(42)
+	nullifier, err := semaphore.MimcHash([]*big.Int{scope, secrets[1]})
+	require.NoError(t, err)
+	sProof := semaphore.SemaphoreProof{
+		MerkleRoot: merkleProof.Root,
+		Nullifier:  nullifier,
+		Message:    big.NewInt(7),
+		Scope:      scope,
+	}
+	proof, err := semaphore.GenerateSemaphoreProof(ccs, pk, secrets[1], merkleProof, sProof)
+	require.NoError(t, err)
+
+	// Invalid proofs are rejected
+	badSProof := sProof
+	badSProof.Message = big.NewInt(8)
+	require.ErrorIs(t, c.ValidateProof(ctx, proof, badSProof), semaphore.ErrInvalidProof)
+	badSProof = sProof
+	badSProof.MerkleRoot = big.NewInt(1)
+	require.ErrorIs(t, c.ValidateProof(ctx, proof, badSProof), semaphore.ErrInvalidMerkleRoot)
+
+	// Valid proofs are accepted once
+	require.NoError(t, c.ValidateProof(ctx, proof, sProof))
+	used, err := c.IsNullifierUsed(ctx, nullifier)
+	require.NoError(t, err)
+	require.True(t, used)
+	require.ErrorIs(t, c.ValidateProof(ctx, proof, sProof), semaphore.ErrNullifierUsed)
+
+	// A watching listener follows the new members
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := NewListener(c.Client(), c.Group())
+	done := make(chan error, 1)
+	go func() { done <- w.Watch(watchCtx) }()
+	require.NoError(t, c.AddMember(ctx, big.NewInt(12345)))
+	require.Eventually(t, func() bool { return w.Size() == n+1 }, 10*time.Second, 10*time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	// Proofs of the previous root are accepted until it expires
+	root, err = c.Root(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, merkleProof.Root, root)
+	prove := func(scope *big.Int, merkleProof leanIMT.MerkleProof) (*groth16_bn254.Proof, semaphore.SemaphoreProof) {
+		nullifier, err := semaphore.MimcHash([]*big.Int{scope, secrets[1]})
+		require.NoError(t, err)
+		sProof := semaphore.SemaphoreProof{
+			MerkleRoot: merkleProof.Root,
+			Nullifier:  nullifier,
+			Message:    big.NewInt(7),
+			Scope:      scope,
+		}
+		proof, err := semaphore.GenerateSemaphoreProof(ccs, pk, secrets[1], merkleProof, sProof)
+		require.NoError(t, err)
+		return proof, sProof
+	}
+	proof, sProof = prove(big.NewInt(43), merkleProof)
+	require.NoError(t, c.ValidateProof(ctx, proof, sProof))
+
+	require.NoError(t, c.backend.AdjustTime(ROOT_DURATION+time.Minute))
+	proof, sProof = prove(big.NewInt(44), merkleProof)
+	err = c.ValidateProof(ctx, proof, sProof)
+	require.ErrorIs(t, err, semaphore.ErrInvalidMerkleRoot)
+	require.ErrorContains(t, err, "Semaphore__MerkleTreeRootIsExpired")
+	_, err = l.Sync(ctx)
+	require.NoError(t, err)
+	merkleProof, err = l.GenerateMerkleProof(1)
+	require.NoError(t, err)
+	require.Equal(t, root, merkleProof.Root)
+	proof, sProof = prove(big.NewInt(44), merkleProof)
+	require.NoError(t, c.ValidateProof(ctx, proof, sProof))
+}
@@ -0,0 +1,356 @@
+package onchain
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// VERIFIER_ABI is the ABI of the verifier exported by gnark for the Semaphore circuit
+const VERIFIER_ABI = `[
+	{"type":"function","name":"verifyProof","stateMutability":"view","inputs":[{"name":"proof","type":"uint256[8]"},{"name":"input","type":"uint256[5]"}],"outputs":[]},
+	{"type":"error","name":"ProofInvalid","inputs":[]},
+	{"type":"error","name":"PublicInputNotInField","inputs":[]}
+]`
+
+// GROUP_ABI is the ABI of the group contract, a single group version of the Semaphore contract
+const GROUP_ABI = `[
+	{"type":"function","name":"addMember","stateMutability":"nonpayable","inputs":[{"name":"identityCommitment","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"validateProof","stateMutability":"nonpayable","inputs":[{"name":"merkleTreeRoot","type":"uint256"},{"name":"nullifier","type":"uint256"},{"name":"message","type":"uint256"},{"name":"scope","type":"uint256"},{"name":"points","type":"uint256[8]"}],"outputs":[]},
+	{"type":"function","name":"getMerkleTreeRoot","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"getMerkleTreeSize","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"isNullifierUsed","stateMutability":"view","inputs":[{"name":"nullifier","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"event","name":"MemberAdded","anonymous":false,"inputs":[{"name":"index","type":"uint256","indexed":false},{"name":"identityCommitment","type":"uint256","indexed":false},{"name":"merkleTreeRoot","type":"uint256","indexed":false}]},
+	{"type":"event","name":"ProofValidated","anonymous":false,"inputs":[{"name":"merkleTreeRoot","type":"uint256","indexed":false},{"name":"nullifier","type":"uint256","indexed":false},{"name":"message","type":"uint256","indexed":false},{"name":"scope","type":"uint256","indexed":false}]},
+	{"type":"error","name":"Semaphore__CallerIsNotTheGroupAdmin","inputs":[]},
+	{"type":"error","name":"Semaphore__InvalidIdentityCommitment","inputs":[]},
+	{"type":"error","name":"Semaphore__MerkleTreeIsFull","inputs":[]},
+	{"type":"error","name":"Semaphore__MerkleTreeRootIsNotPartOfTheGroup","inputs":[]},
+	{"type":"error","name":"Semaphore__MerkleTreeRootIsExpired","inputs":[]},
+	{"type":"error","name":"Semaphore__YouAreUsingTheSameNullifierTwice","inputs":[]},
+	{"type":"error","name":"Semaphore__InvalidProof","inputs":[]}
+]`
+
+var (
+	verifierABI = mustParseABI(VERIFIER_ABI)
+	groupABI    = mustParseABI(GROUP_ABI)
+)
+
+func mustParseABI(s string) abi.ABI {
+	res, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// ExportSolidityVerifier writes the Solidity source of the verifier of `vk`, as exported by gnark.
+// VerifierCode assembles an equivalent contract so that the simulated chain doesn't need solc
+func ExportSolidityVerifier(vk groth16.VerifyingKey, w io.Writer) error {
+	return vk.ExportSolidity(w)
+}
+
+// VerifierCode returns the runtime code of the verifier of `vk`. It implements the
+// verifyProof(uint256[8] proof, uint256[5] input) function of the verifier exported
+// by gnark, which reverts if the proof is invalid:
+//
+//	e(-A, B) · e(α, β) · e(K₀ + Σ inputᵢ·Kᵢ₊₁, γ) · e(C, δ) = 1
+//
+// using the ecAdd, ecMul and ecPairing precompiles
+func VerifierCode(vk groth16.VerifyingKey) ([]byte, error) {
+	bvk, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return nil, fmt.Errorf("the verifying key isn't a bn254 verifying key")
+	}
+	if len(bvk.CommitmentKeys) != 0 {
+		return nil, fmt.Errorf("the verifier doesn't support commitments")
+	}
+	nbPublic := len(bvk.G1.K) - 1
+	if nbPublic != 5 {
+		return nil, fmt.Errorf("the verifying key has %d public inputs, expected 5", nbPublic)
+	}
+
+	p, r := fp.Modulus(), fr.Modulus()
+	const (
+		proofOffset = 4
+		inputOffset = proofOffset + 8*32
+		pairingMem  = 0x100 // 4 pairs of 6 words
+	)
+
+	a := newAssembler()
+
+	// Dispatch
+	a.pushInt(0).op(vm.CALLDATALOAD).pushInt(0xe0).op(vm.SHR)
+	a.pushBytes(verifierABI.Methods["verifyProof"].ID).op(vm.EQ).jumpi("verifyProof")
+	a.pushInt(0).op(vm.DUP1, vm.REVERT)
+
+	a.label("verifyProof")
+	a.pushInt(inputOffset+uint64(nbPublic)*32).op(vm.CALLDATASIZE, vm.LT).jumpi("invalid")
+
+	// The coordinates of the proof are elements of fp
+	for i := uint64(0); i < 8; i++ {
+		a.pushInt(proofOffset+32*i).op(vm.CALLDATALOAD).push(p).op(vm.GT, vm.ISZERO).jumpi("invalid")
+	}
+
+	// K = K₀ + Σ inputᵢ·Kᵢ₊₁ at memory 0x00, using 0x40 for the products and 0x80 for the ecMul inputs
+	k0 := g1Words(bvk.G1.K[0])
+	a.mstore(0x00, k0[0]).mstore(0x20, k0[1])
+	for i := 0; i < nbPublic; i++ {
+		ki := g1Words(bvk.G1.K[i+1])
+		a.mstore(0x80, ki[0]).mstore(0xa0, ki[1])
+		a.pushInt(inputOffset + uint64(i)*32).op(vm.CALLDATALOAD)
+		a.op(vm.DUP1).push(r).op(vm.GT, vm.ISZERO).jumpi("inputNotInField")
+		a.pushInt(0xc0).op(vm.MSTORE)
+		a.staticcall(7, 0x80, 0x60, 0x40, 0x40).op(vm.ISZERO).jumpi("invalid")
+		a.staticcall(6, 0x00, 0x80, 0x00, 0x40).op(vm.ISZERO).jumpi("invalid")
+	}
+
+	// e(-A, B)
+	a.pushInt(proofOffset).op(vm.CALLDATALOAD).pushInt(pairingMem).op(vm.MSTORE)
+	a.pushInt(proofOffset+32).op(vm.CALLDATALOAD).push(p).op(vm.SUB).push(p).op(vm.SWAP1, vm.MOD)
+	a.pushInt(pairingMem + 0x20).op(vm.MSTORE)
+	a.pushInt(4 * 32).pushInt(proofOffset + 2*32).pushInt(pairingMem + 0x40).op(vm.CALLDATACOPY)
+
+	// e(α, β)
+	words := append(g1Words(bvk.G1.Alpha), g2Words(bvk.G2.Beta)...)
+	for i, w := range words {
+		a.mstore(pairingMem+0xc0+uint64(i)*32, w)
+	}
+
+	// e(K, γ)
+	a.pushInt(0x00).op(vm.MLOAD).pushInt(pairingMem + 0x180).op(vm.MSTORE)
+	a.pushInt(0x20).op(vm.MLOAD).pushInt(pairingMem + 0x1a0).op(vm.MSTORE)
+	for i, w := range g2Words(bvk.G2.Gamma) {
+		a.mstore(pairingMem+0x1c0+uint64(i)*32, w)
+	}
+
+	// e(C, δ)
+	a.pushInt(2 * 32).pushInt(proofOffset + 6*32).pushInt(pairingMem + 0x240).op(vm.CALLDATACOPY)
+	for i, w := range g2Words(bvk.G2.Delta) {
+		a.mstore(pairingMem+0x280+uint64(i)*32, w)
+	}
+
+	a.staticcall(8, pairingMem, 0x300, 0x00, 0x20).op(vm.ISZERO).jumpi("invalid")
+	a.pushInt(0x00).op(vm.MLOAD, vm.ISZERO).jumpi("invalid")
+	a.op(vm.STOP)
+
+	a.label("inputNotInField").revertWith(errorSelector(verifierABI, "PublicInputNotInField"))
+	a.label("invalid").revertWith(errorSelector(verifierABI, "ProofInvalid"))
+	return a.bytes()
+}
+
+// GroupCode returns the runtime code of a group contract using `verifier` to verify
+// the semaphore proofs. The contract inserts the members added by `admin` in its own
+// LeanIMT, hashing the nodes with MiMC as semaphore.MimcHash does, so that its root
+// doesn't depend on the admin. Proofs are accepted for the current root, or for a
+// previous root during `rootDuration` after it was replaced.
+//
+// Storage: slot 0 is the size of the group, slot 1 its root and slot 3 its depth.
+// A nullifier n is used if the slot keccak256(n . 2) is set, a previous root is replaced
+// at the timestamp stored in keccak256(root . 4), and the side node of a level is
+// stored in keccak256(level . 5)
+func GroupCode(verifier, admin common.Address, rootDuration time.Duration) ([]byte, error) {
+	if rootDuration <= 0 {
+		return nil, fmt.Errorf("the duration of the roots must be positive")
+	}
+	r := fr.Modulus()
+	const (
+		index = 0x100 // index of the added member
+		depth = 0x120 // depth of the tree
+		node  = 0x140 // node of the current level
+		level = 0x160 // current level
+		key   = 0x180 // MiMC key
+	)
+	a := newAssembler()
+
+	// Dispatch
+	a.pushInt(0).op(vm.CALLDATALOAD).pushInt(0xe0).op(vm.SHR)
+	for _, name := range []string{"addMember", "validateProof", "getMerkleTreeRoot", "getMerkleTreeSize", "isNullifierUsed"} {
+		a.op(vm.DUP1).pushBytes(groupABI.Methods[name].ID).op(vm.EQ).jumpi(name)
+	}
+	a.pushInt(0).op(vm.DUP1, vm.REVERT)
+
+	// addMember(identityCommitment)
+	a.label("addMember")
+	a.op(vm.CALLER).pushBytes(admin.Bytes()).op(vm.EQ, vm.ISZERO).jumpi("notAdmin")
+	a.pushInt(4).op(vm.CALLDATALOAD, vm.ISZERO).jumpi("invalidCommitment")
+	a.pushInt(4).op(vm.CALLDATALOAD).push(r).op(vm.GT, vm.ISZERO).jumpi("invalidCommitment")
+	a.pushInt(0).op(vm.SLOAD, vm.DUP1).pushInt(index).op(vm.MSTORE)
+	a.pushInt(1<<semaphore.MAX_DEPTH).op(vm.SWAP1, vm.LT, vm.ISZERO).jumpi("groupFull")
+
+	// The tree gets a new level if it is full: 2^depth < index + 1
+	a.pushInt(3).op(vm.SLOAD)
+	a.pushInt(index).op(vm.MLOAD).pushInt(1).op(vm.ADD)
+	a.pushInt(1).op(vm.DUP3, vm.SHL, vm.LT, vm.ADD)
+	a.op(vm.DUP1).pushInt(3).op(vm.SSTORE).pushInt(depth).op(vm.MSTORE)
+
+	// Hash the new leaf up to the root: a right node is hashed with the side node
+	// of its level, a left node becomes the side node of its level, as the root
+	// becomes the side node of the depth of the tree
+	a.pushInt(4).op(vm.CALLDATALOAD).pushInt(node).op(vm.MSTORE)
+	a.pushInt(0).pushInt(level).op(vm.MSTORE)
+	a.label("insertLevel")
+	a.pushInt(depth).op(vm.MLOAD).pushInt(level).op(vm.MLOAD).op(vm.LT, vm.ISZERO).jumpi("inserted")
+	a.pushInt(index).op(vm.MLOAD).pushInt(level).op(vm.MLOAD).op(vm.SHR).pushInt(1).op(vm.AND).jumpi("rightNode")
+	a.pushInt(node).op(vm.MLOAD).pushInt(level).op(vm.MLOAD).mappingSlot(5).op(vm.SSTORE)
+	a.jump("nextLevel")
+	a.label("rightNode")
+	a.pushInt(level).op(vm.MLOAD).mappingSlot(5).op(vm.SLOAD).pushInt(node).op(vm.MLOAD)
+	a.mimcPair(key, r).pushInt(node).op(vm.MSTORE)
+	a.label("nextLevel")
+	a.pushInt(level).op(vm.MLOAD).pushInt(1).op(vm.ADD).pushInt(level).op(vm.MSTORE)
+	a.jump("insertLevel")
+
+	// Record the time the previous root is replaced, then update the root and the size
+	a.label("inserted")
+	a.pushInt(node).op(vm.MLOAD).pushInt(depth).op(vm.MLOAD).mappingSlot(5).op(vm.SSTORE)
+	a.pushInt(index).op(vm.MLOAD, vm.ISZERO).jumpi("updateRoot")
+	a.op(vm.TIMESTAMP).pushInt(1).op(vm.SLOAD).mappingSlot(4).op(vm.SSTORE)
+	a.label("updateRoot")
+	a.pushInt(node).op(vm.MLOAD).pushInt(1).op(vm.SSTORE)
+	a.pushInt(index).op(vm.MLOAD).pushInt(1).op(vm.ADD).pushInt(0).op(vm.SSTORE)
+	a.pushInt(index).op(vm.MLOAD).pushInt(0x00).op(vm.MSTORE)
+	a.pushInt(4).op(vm.CALLDATALOAD).pushInt(0x20).op(vm.MSTORE)
+	a.pushInt(node).op(vm.MLOAD).pushInt(0x40).op(vm.MSTORE)
+	a.pushBytes(groupABI.Events["MemberAdded"].ID.Bytes()).pushInt(0x60).pushInt(0).op(vm.LOG1)
+	a.op(vm.STOP)
+
+	// validateProof(merkleTreeRoot, nullifier, message, scope, points)
+	a.label("validateProof")
+	a.pushInt(0).op(vm.SLOAD, vm.ISZERO).jumpi("invalidRoot")
+	a.pushInt(4).op(vm.CALLDATALOAD).pushInt(1).op(vm.SLOAD, vm.EQ).jumpi("validRoot")
+	a.pushInt(4).op(vm.CALLDATALOAD).mappingSlot(4).op(vm.SLOAD)
+	a.op(vm.DUP1, vm.ISZERO).jumpi("invalidRoot")
+	a.pushInt(uint64(rootDuration/time.Second)).op(vm.ADD, vm.TIMESTAMP, vm.GT).jumpi("expiredRoot")
+	a.label("validRoot")
+	a.nullifierSlot(36).op(vm.SLOAD).jumpi("nullifierUsed")
+
+	// verifyProof(points, [message, scope, message², merkleTreeRoot, nullifier])
+	a.push(selectorWord(verifierABI.Methods["verifyProof"].ID)).pushInt(0).op(vm.MSTORE)
+	a.pushInt(8 * 32).pushInt(4 + 4*32).pushInt(4).op(vm.CALLDATACOPY)
+	inputs := uint64(4 + 8*32)
+	a.pushInt(68).op(vm.CALLDATALOAD).pushInt(inputs).op(vm.MSTORE)
+	a.pushInt(100).op(vm.CALLDATALOAD).pushInt(inputs + 0x20).op(vm.MSTORE)
+	a.push(r).pushInt(68).op(vm.CALLDATALOAD, vm.DUP1, vm.MULMOD).pushInt(inputs + 0x40).op(vm.MSTORE)
+	a.pushInt(4).op(vm.CALLDATALOAD).pushInt(inputs + 0x60).op(vm.MSTORE)
+	a.pushInt(36).op(vm.CALLDATALOAD).pushInt(inputs + 0x80).op(vm.MSTORE)
+	a.pushInt(0).pushInt(0).pushInt(inputs+5*32).pushInt(0).pushBytes(verifier.Bytes()).op(vm.GAS, vm.STATICCALL)
+	a.op(vm.ISZERO).jumpi("invalidProof")
+
+	a.pushInt(1).nullifierSlot(36).op(vm.SSTORE)
+	a.pushInt(4 * 32).pushInt(4).pushInt(0).op(vm.CALLDATACOPY)
+	a.pushBytes(groupABI.Events["ProofValidated"].ID.Bytes()).pushInt(4 * 32).pushInt(0).op(vm.LOG1)
+	a.op(vm.STOP)
+
+	// Views
+	a.label("getMerkleTreeRoot").pushInt(1).op(vm.SLOAD).returnWord()
+	a.label("getMerkleTreeSize").pushInt(0).op(vm.SLOAD).returnWord()
+	a.label("isNullifierUsed").pushInt(4).op(vm.CALLDATALOAD).mappingSlot(2).op(vm.SLOAD, vm.ISZERO, vm.ISZERO).returnWord()
+
+	a.mimcEncrypt(key, r)
+
+	// Errors
+	a.label("notAdmin").revertWith(errorSelector(groupABI, "Semaphore__CallerIsNotTheGroupAdmin"))
+	a.label("invalidCommitment").revertWith(errorSelector(groupABI, "Semaphore__InvalidIdentityCommitment"))
+	a.label("groupFull").revertWith(errorSelector(groupABI, "Semaphore__MerkleTreeIsFull"))
+	a.label("invalidRoot").revertWith(errorSelector(groupABI, "Semaphore__MerkleTreeRootIsNotPartOfTheGroup"))
+	a.label("expiredRoot").revertWith(errorSelector(groupABI, "Semaphore__MerkleTreeRootIsExpired"))
+	a.label("nullifierUsed").revertWith(errorSelector(groupABI, "Semaphore__YouAreUsingTheSameNullifierTwice"))
+	a.label("invalidProof").revertWith(errorSelector(groupABI, "Semaphore__InvalidProof"))
+	return a.bytes()
+}
+
+// mimcPair replaces the two nodes on the top of the stack, the right node on top,
+// with MiMC(left, right), calling the encrypt subroutine with the key at memory `key`
+func (a *assembler) mimcPair(key uint64, r *big.Int) *assembler {
+	// h = encrypt(left, 0) + left
+	a.pushInt(0).pushInt(key).op(vm.MSTORE)
+	a.op(vm.SWAP1, vm.DUP1).pushLabel("mimcLeft").op(vm.SWAP1).jump("mimcEncrypt")
+	a.label("mimcLeft").op(vm.ADD).push(r).op(vm.SWAP1, vm.MOD)
+
+	// h = encrypt(right, h) + h + right
+	a.op(vm.DUP1).pushInt(key).op(vm.MSTORE)
+	a.op(vm.DUP2).pushLabel("mimcRight").op(vm.SWAP1).jump("mimcEncrypt")
+	a.label("mimcRight").op(vm.ADD, vm.ADD).push(r).op(vm.SWAP1, vm.MOD)
+	return a
+}
+
+// mimcEncrypt appends the encrypt subroutine of MiMC, called with the message on the top
+// of the stack above the return label. It runs the rounds m = (m + k + cᵢ)⁵ with the key
+// k at memory `key` and returns m + k
+func (a *assembler) mimcEncrypt(key uint64, r *big.Int) *assembler {
+	a.label("mimcEncrypt")
+	constants := mimc.GetConstants()
+	for i := range constants {
+		a.pushInt(key).op(vm.MLOAD, vm.ADD).push(&constants[i]).op(vm.ADD)
+		a.push(r).op(vm.DUP2, vm.DUP1, vm.MULMOD)
+		a.push(r).op(vm.DUP2, vm.DUP1, vm.MULMOD, vm.SWAP1, vm.POP)
+		a.push(r).op(vm.SWAP2, vm.MULMOD)
+	}
+	a.pushInt(key).op(vm.MLOAD, vm.ADD).push(r).op(vm.SWAP1, vm.MOD)
+	return a.op(vm.SWAP1, vm.JUMP)
+}
+
+// staticcall calls a precompile and pushes its success flag
+func (a *assembler) staticcall(precompile, argsOffset, argsSize, retOffset, retSize uint64) *assembler {
+	return a.pushInt(retSize).pushInt(retOffset).pushInt(argsSize).pushInt(argsOffset).
+		pushInt(precompile).op(vm.GAS, vm.STATICCALL)
+}
+
+// nullifierSlot pushes keccak256(n . 2), with n the calldata word at `offset`
+func (a *assembler) nullifierSlot(offset uint64) *assembler {
+	return a.pushInt(offset).op(vm.CALLDATALOAD).mappingSlot(2)
+}
+
+// mappingSlot replaces the key on the top of the stack with the slot keccak256(key . base)
+// of the mapping at slot `base`, using the memory 0x00-0x40
+func (a *assembler) mappingSlot(base uint64) *assembler {
+	a.pushInt(0x00).op(vm.MSTORE)
+	a.pushInt(base).pushInt(0x20).op(vm.MSTORE)
+	return a.pushInt(0x40).pushInt(0x00).op(vm.KECCAK256)
+}
+
+// returnWord returns the top of the stack
+func (a *assembler) returnWord() *assembler {
+	return a.pushInt(0x00).op(vm.MSTORE).pushInt(0x20).pushInt(0x00).op(vm.RETURN)
+}
+
+// selectorWord returns the memory word starting with a 4 bytes selector
+func selectorWord(selector []byte) *big.Int {
+	word := make([]byte, 32)
+	copy(word, selector)
+	return new(big.Int).SetBytes(word)
+}
+
+// g1Words returns the coordinates of a G1 point in the order of the precompiles
+func g1Words(p bn254.G1Affine) []*big.Int {
+	return []*big.Int{p.X.BigInt(new(big.Int)), p.Y.BigInt(new(big.Int))}
+}
+
+// g2Words returns the coordinates of a G2 point in the order of the precompiles,
+// the imaginary part first
+func g2Words(p bn254.G2Affine) []*big.Int {
+	return []*big.Int{
+		p.X.A1.BigInt(new(big.Int)), p.X.A0.BigInt(new(big.Int)),
+		p.Y.A1.BigInt(new(big.Int)), p.Y.A0.BigInt(new(big.Int)),
+	}
+}
+
+// errorSelector returns the 4 bytes selector of a custom error
+func errorSelector(contract abi.ABI, name string) []byte {
+	id := contract.Errors[name].ID
+	return id[:4]
+}
@@ -0,0 +1,167 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogBackend is the part of an Ethereum client used by a Listener
+type LogBackend interface {
+	ethereum.LogFilterer
+	ethereum.BlockNumberReader
+}
+
+// Listener keeps a local LeanIMT in sync with the MemberAdded events of a
+// group contract, checking the root of each event
+type Listener struct {
+	backend LogBackend
+	group   common.Address
+
+	mu        sync.RWMutex
+	imt       *leanIMT.LeanIMT
+	fromBlock uint64 // next block to scan
+}
+
+// NewListener returns a listener of the group contract at `group`
+func NewListener(backend LogBackend, group common.Address) *Listener {
+	imt, _ := leanIMT.NewLeanIMT(semaphore.MimcHash, []*big.Int{})
+	return &Listener{backend: backend, group: group, imt: imt}
+}
+
+// Sync applies the MemberAdded events up to the latest block,
+// and returns the number of added members
+func (l *Listener) Sync(ctx context.Context) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	head, err := l.backend.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if head < l.fromBlock {
+		return 0, nil
+	}
+	logs, err := l.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(l.fromBlock),
+		ToBlock:   new(big.Int).SetUint64(head),
+		Addresses: []common.Address{l.group},
+		Topics:    [][]common.Hash{{groupABI.Events["MemberAdded"].ID}},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, log := range logs {
+		ok, err := l.apply(log)
+		if err != nil {
+			// Retry from the block of the invalid event
+			l.fromBlock = log.BlockNumber
+			return added, err
+		}
+		if ok {
+			added++
+		}
+	}
+	l.fromBlock = head + 1
+	return added, nil
+}
+
+// Watch syncs the listener each time the group contract emits a MemberAdded
+// event, until `ctx` is done or an event doesn't match the local tree
+func (l *Listener) Watch(ctx context.Context) error {
+	logs := make(chan types.Log)
+	sub, err := l.backend.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{l.group},
+		Topics:    [][]common.Hash{{groupABI.Events["MemberAdded"].ID}},
+	}, logs)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	// Catch up with the events emitted before the subscription
+	if _, err := l.Sync(ctx); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-logs:
+			if _, err := l.Sync(ctx); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// apply adds the member of a MemberAdded log to the tree, it returns false
+// if the member has already been added
+func (l *Listener) apply(log types.Log) (bool, error) {
+	values, err := groupABI.Unpack("MemberAdded", log.Data)
+	if err != nil {
+		return false, err
+	}
+	index := values[0].(*big.Int)
+	size := l.imt.Size()
+	if !index.IsInt64() || index.Int64() > int64(size) {
+		return false, fmt.Errorf("invalid index %v", index)
+	}
+	if index.Int64() < int64(size) {
+		return false, nil
+	}
+
+	err = semaphore.ApplyEvent(l.imt, semaphore.GroupEvent{
+		Seq:           index.Uint64(),
+		Type:          semaphore.EventMemberAdded,
+		Index:         size,
+		NewCommitment: values[1].(*big.Int),
+		Root:          values[2].(*big.Int),
+	})
+	if err != nil {
+		// Drop the member if it was inserted before the roots were compared
+		if l.imt.Size() != size {
+			l.imt, _ = leanIMT.NewLeanIMT(semaphore.MimcHash, l.imt.Nodes[0][:size])
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Size returns the number of members of the local tree
+func (l *Listener) Size() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.imt.Size()
+}
+
+// Root returns the root of the local tree, or nil if the group is empty
+func (l *Listener) Root() *big.Int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.imt.Size() == 0 {
+		return nil
+	}
+	return new(big.Int).Set(l.imt.Root())
+}
+
+// GenerateMerkleProof returns the merkle proof of the member at `idx` of the local tree
+func (l *Listener) GenerateMerkleProof(idx int) (leanIMT.MerkleProof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if idx < 0 || idx >= l.imt.Size() {
+		return leanIMT.MerkleProof{}, fmt.Errorf("invalid index")
+	}
+	return l.imt.GenerateProof(idx)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+)
+
+// runSetup handles the `setup` command
+func runSetup(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("setup", flag.ContinueOnError)
+	keysDir := fs.String("keys", "keys", "output directory of the circuit and its keys")
+	nullifierMode := fs.String("nullifier", "", "nullifier mode of the circuit: per_scope (default), per_epoch or per_app")
+	timestamped := fs.Bool("timestamp", false, "setup the circuit of timestamped proofs, whose nullifiers are per scope")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	mode, err := parseNullifierMode(*nullifierMode)
+	if err != nil {
+		return err
+	}
+	if *timestamped && mode != semaphore.NULLIFIER_PER_SCOPE {
+		return fmt.Errorf("timestamped proofs only have per scope nullifiers")
+	}
+
+	var ccs constraint.ConstraintSystem
+	var pk groth16.ProvingKey
+	var vk groth16.VerifyingKey
+	if *timestamped {
+		ccs, pk, vk, err = semaphore.SetupTimestampedCircuit()
+	} else {
+		ccs, pk, vk, err = semaphore.SetupNullifierCircuit(mode)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeKeys(*keysDir, ccs, pk, vk); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "circuit and keys written to %s\n", *keysDir)
+	return nil
+}
+
+// runProve handles the `prove` command
+func runProve(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("prove", flag.ContinueOnError)
+	keysDir := fs.String("keys", "keys", "directory of the circuit and its keys")
+	identityPath := fs.String("identity", "identity.json", "identity file")
+	groupPath := fs.String("group", "group.json", "group file")
+	message := fs.String("message", "", "message to signal")
+	scope := fs.String("scope", "", "scope of the signal")
+	domainPath := fs.String("domain", "", "domain file, binds the proof to the domain if set")
+	nullifierMode := fs.String("nullifier", "", "nullifier mode of the keys: per_scope (default), per_epoch or per_app")
+	nullifierInput := fs.String("nullifier-input", "", "epoch or app salt of the nullifier, with -nullifier per_epoch or per_app")
+	timestamped := fs.Bool("timestamp", false, "timestamp the proof with the current time, with the keys of a timestamped circuit")
+	out := fs.String("out", "", "output proof file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *message == "" || *scope == "" {
+		return fmt.Errorf("both -message and -scope are required")
+	}
+	msg, err := parseBigInt(*message)
+	if err != nil {
+		return err
+	}
+	scp, err := parseBigInt(*scope)
+	if err != nil {
+		return err
+	}
+	domain, err := loadDomain(*domainPath)
+	if err != nil {
+		return err
+	}
+	mode, err := parseNullifierMode(*nullifierMode)
+	if err != nil {
+		return err
+	}
+	var input *big.Int
+	if mode != semaphore.NULLIFIER_PER_SCOPE {
+		if *nullifierInput == "" {
+			return fmt.Errorf("the %v nullifier needs -nullifier-input", mode)
+		}
+		if input, err = parseBigInt(*nullifierInput); err != nil {
+			return err
+		}
+	}
+
+	// Find the member in the group
+	secret, err := loadIdentity(*identityPath)
+	if err != nil {
+		return err
+	}
+	idc, err := semaphore.MimcHash([]*big.Int{secret})
+	if err != nil {
+		return err
+	}
+	imt, err := loadGroup(*groupPath)
+	if err != nil {
+		return err
+	}
+	idx, err := indexOf(imt, idc)
+	if err != nil {
+		return err
+	}
+	merkleProof, err := imt.GenerateProof(idx)
+	if err != nil {
+		return err
+	}
+
+	// Generate the proof, the nullifier is computed with the scope bound to the domain
+	circuitScope := scp
+	if domain != nil {
+		if circuitScope, err = domain.Scope(scp); err != nil {
+			return err
+		}
+	}
+	nullifier, err := semaphore.ComputeNullifier(mode, secret, circuitScope, input)
+	if err != nil {
+		return err
+	}
+	sProof := semaphore.SemaphoreProof{
+		MerkleRoot:     merkleProof.Root,
+		Nullifier:      nullifier,
+		Message:        msg,
+		Scope:          scp,
+		NullifierMode:  mode,
+		NullifierInput: input,
+		Domain:         domain,
+	}
+	if *timestamped {
+		sProof.Timestamp = semaphore.Timestamp(time.Now())
+	}
+	ccs, pk, err := readProverKeys(*keysDir)
+	if err != nil {
+		return err
+	}
+	proof, err := semaphore.GenerateSemaphoreProof(ccs, pk, secret, merkleProof, sProof)
+	if err != nil {
+		return err
+	}
+
+	f, err := encodeProof(proof, sProof)
+	if err != nil {
+		return err
+	}
+	return writeJSON(*out, stdout, f)
+}
+
+// sameDomain returns true if the domain of a proof, possibly nil, hashes to `domain`
+func sameDomain(proofDomain, domain *semaphore.Domain) bool {
+	if proofDomain == nil {
+		return false
+	}
+	a, errA := proofDomain.Hash()
+	b, errB := domain.Hash()
+	return errA == nil && errB == nil && a.Cmp(b) == 0
+}
+
+// runVerify handles the `verify` command
+func runVerify(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	keysDir := fs.String("keys", "keys", "directory of the circuit and its keys")
+	proofPath := fs.String("proof", "proof.json", "proof file")
+	groupPath := fs.String("group", "", "group file, checks the proof root against the group root if set")
+	domainPath := fs.String("domain", "", "domain file, requires the proof to be bound to the domain if set")
+	maxClockSkew := fs.Duration("max-clock-skew", 0, "requires the proof to be timestamped within this duration of the current time if set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var f ProofFile
+	if err := readJSON(*proofPath, &f); err != nil {
+		return err
+	}
+	proof, sProof, err := decodeProof(f)
+	if err != nil {
+		return err
+	}
+
+	domain, err := loadDomain(*domainPath)
+	if err != nil {
+		return err
+	}
+	if domain != nil && !sameDomain(sProof.Domain, domain) {
+		return semaphore.ErrWrongDomain
+	}
+
+	if *maxClockSkew > 0 {
+		if sProof.Timestamp == nil || !sProof.Timestamp.IsInt64() {
+			return fmt.Errorf("the proof isn't timestamped")
+		}
+		if skew := time.Since(time.Unix(sProof.Timestamp.Int64(), 0)).Abs(); skew > *maxClockSkew {
+			return fmt.Errorf("the clock skew of the proof is %v", skew.Round(time.Second))
+		}
+	}
+
+	if *groupPath != "" {
+		imt, err := loadGroup(*groupPath)
+		if err != nil {
+			return err
+		}
+		if imt.Size() == 0 || imt.Root().Cmp(sProof.MerkleRoot) != 0 {
+			return fmt.Errorf("invalid merkle root")
+		}
+	}
+
+	vk, err := readVerifyingKey(*keysDir)
+	if err != nil {
+		return err
+	}
+	if err := semaphore.VerifySemaphoreProof(vk, proof, sProof); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "proof is valid")
+	return nil
+}
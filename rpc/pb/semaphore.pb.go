@@ -0,0 +1,1346 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: semaphore/v1/semaphore.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GroupEvent_Type int32
+
+const (
+	GroupEvent_TYPE_UNSPECIFIED    GroupEvent_Type = 0
+	GroupEvent_TYPE_MEMBER_ADDED   GroupEvent_Type = 1
+	GroupEvent_TYPE_MEMBER_UPDATED GroupEvent_Type = 2
+	GroupEvent_TYPE_MEMBER_REMOVED GroupEvent_Type = 3
+)
+
+// Enum value maps for GroupEvent_Type.
+var (
+	GroupEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_MEMBER_ADDED",
+		2: "TYPE_MEMBER_UPDATED",
+		3: "TYPE_MEMBER_REMOVED",
+	}
+	GroupEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED":    0,
+		"TYPE_MEMBER_ADDED":   1,
+		"TYPE_MEMBER_UPDATED": 2,
+		"TYPE_MEMBER_REMOVED": 3,
+	}
+)
+
+func (x GroupEvent_Type) Enum() *GroupEvent_Type {
+	p := new(GroupEvent_Type)
+	*p = x
+	return p
+}
+
+func (x GroupEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_semaphore_v1_semaphore_proto_enumTypes[0].Descriptor()
+}
+
+func (GroupEvent_Type) Type() protoreflect.EnumType {
+	return &file_semaphore_v1_semaphore_proto_enumTypes[0]
+}
+
+func (x GroupEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupEvent_Type.Descriptor instead.
+func (GroupEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{18, 0}
+}
+
+// IdentityCommitment is the public identity of a group member
+type IdentityCommitment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IdentityCommitment) Reset() {
+	*x = IdentityCommitment{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IdentityCommitment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IdentityCommitment) ProtoMessage() {}
+
+func (x *IdentityCommitment) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IdentityCommitment.ProtoReflect.Descriptor instead.
+func (*IdentityCommitment) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IdentityCommitment) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// MerkleProof proves that a leaf belongs to a group tree
+type MerkleProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Leaf          []byte                 `protobuf:"bytes,2,opt,name=leaf,proto3" json:"leaf,omitempty"`
+	Root          []byte                 `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	Path          []uint32               `protobuf:"varint,4,rep,packed,name=path,proto3" json:"path,omitempty"` // 0: left, 1: right
+	Siblings      [][]byte               `protobuf:"bytes,5,rep,name=siblings,proto3" json:"siblings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerkleProof) Reset() {
+	*x = MerkleProof{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleProof) ProtoMessage() {}
+
+func (x *MerkleProof) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleProof.ProtoReflect.Descriptor instead.
+func (*MerkleProof) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MerkleProof) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *MerkleProof) GetLeaf() []byte {
+	if x != nil {
+		return x.Leaf
+	}
+	return nil
+}
+
+func (x *MerkleProof) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *MerkleProof) GetPath() []uint32 {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+func (x *MerkleProof) GetSiblings() [][]byte {
+	if x != nil {
+		return x.Siblings
+	}
+	return nil
+}
+
+// Domain identifies a deployment of a verifier, it is hashed into the scope of the proofs
+type Domain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AppId         string                 `protobuf:"bytes,1,opt,name=app_id,json=appId,proto3" json:"app_id,omitempty"`
+	ChainId       uint64                 `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Verifier      []byte                 `protobuf:"bytes,3,opt,name=verifier,proto3" json:"verifier,omitempty"` // address of the verifier, e.g. its contract address
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Domain) Reset() {
+	*x = Domain{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Domain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Domain) ProtoMessage() {}
+
+func (x *Domain) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Domain.ProtoReflect.Descriptor instead.
+func (*Domain) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Domain) GetAppId() string {
+	if x != nil {
+		return x.AppId
+	}
+	return ""
+}
+
+func (x *Domain) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *Domain) GetVerifier() []byte {
+	if x != nil {
+		return x.Verifier
+	}
+	return nil
+}
+
+// SemaphoreProof holds the public signals of a semaphore proof
+type SemaphoreProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerkleRoot    []byte                 `protobuf:"bytes,1,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	Nullifier     []byte                 `protobuf:"bytes,2,opt,name=nullifier,proto3" json:"nullifier,omitempty"`
+	Message       []byte                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Scope         []byte                 `protobuf:"bytes,4,opt,name=scope,proto3" json:"scope,omitempty"`
+	Domain        *Domain                `protobuf:"bytes,5,opt,name=domain,proto3" json:"domain,omitempty"` // unset if the proof isn't bound to a domain
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SemaphoreProof) Reset() {
+	*x = SemaphoreProof{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SemaphoreProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SemaphoreProof) ProtoMessage() {}
+
+func (x *SemaphoreProof) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SemaphoreProof.ProtoReflect.Descriptor instead.
+func (*SemaphoreProof) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SemaphoreProof) GetMerkleRoot() []byte {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return nil
+}
+
+func (x *SemaphoreProof) GetNullifier() []byte {
+	if x != nil {
+		return x.Nullifier
+	}
+	return nil
+}
+
+func (x *SemaphoreProof) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SemaphoreProof) GetScope() []byte {
+	if x != nil {
+		return x.Scope
+	}
+	return nil
+}
+
+func (x *SemaphoreProof) GetDomain() *Domain {
+	if x != nil {
+		return x.Domain
+	}
+	return nil
+}
+
+// Groth16Proof is a gnark serialized (compressed) BN254 groth16 proof
+type Groth16Proof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Groth16Proof) Reset() {
+	*x = Groth16Proof{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Groth16Proof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Groth16Proof) ProtoMessage() {}
+
+func (x *Groth16Proof) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Groth16Proof.ProtoReflect.Descriptor instead.
+func (*Groth16Proof) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Groth16Proof) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Group struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Size          uint32                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Depth         uint32                 `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	Root          []byte                 `protobuf:"bytes,4,opt,name=root,proto3" json:"root,omitempty"`     // empty if the group has no member
+	Domain        *Domain                `protobuf:"bytes,5,opt,name=domain,proto3" json:"domain,omitempty"` // unset if the group isn't bound to a domain
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Group) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *Group) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Group) GetDepth() uint32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *Group) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *Group) GetDomain() *Domain {
+	if x != nil {
+		return x.Domain
+	}
+	return nil
+}
+
+type CreateGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Members       []*IdentityCommitment  `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	Domain        *Domain                `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"` // binds the group to a domain
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGroupRequest) Reset() {
+	*x = CreateGroupRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGroupRequest) ProtoMessage() {}
+
+func (x *CreateGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGroupRequest.ProtoReflect.Descriptor instead.
+func (*CreateGroupRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateGroupRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *CreateGroupRequest) GetMembers() []*IdentityCommitment {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *CreateGroupRequest) GetDomain() *Domain {
+	if x != nil {
+		return x.Domain
+	}
+	return nil
+}
+
+type GetGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupRequest) Reset() {
+	*x = GetGroupRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupRequest) ProtoMessage() {}
+
+func (x *GetGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupRequest.ProtoReflect.Descriptor instead.
+func (*GetGroupRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetGroupRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+type AddMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Commitment    *IdentityCommitment    `protobuf:"bytes,2,opt,name=commitment,proto3" json:"commitment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddMemberRequest) Reset() {
+	*x = AddMemberRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddMemberRequest) ProtoMessage() {}
+
+func (x *AddMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddMemberRequest.ProtoReflect.Descriptor instead.
+func (*AddMemberRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AddMemberRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *AddMemberRequest) GetCommitment() *IdentityCommitment {
+	if x != nil {
+		return x.Commitment
+	}
+	return nil
+}
+
+type UpdateMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	OldCommitment *IdentityCommitment    `protobuf:"bytes,2,opt,name=old_commitment,json=oldCommitment,proto3" json:"old_commitment,omitempty"`
+	NewCommitment *IdentityCommitment    `protobuf:"bytes,3,opt,name=new_commitment,json=newCommitment,proto3" json:"new_commitment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMemberRequest) Reset() {
+	*x = UpdateMemberRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMemberRequest) ProtoMessage() {}
+
+func (x *UpdateMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMemberRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMemberRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateMemberRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *UpdateMemberRequest) GetOldCommitment() *IdentityCommitment {
+	if x != nil {
+		return x.OldCommitment
+	}
+	return nil
+}
+
+func (x *UpdateMemberRequest) GetNewCommitment() *IdentityCommitment {
+	if x != nil {
+		return x.NewCommitment
+	}
+	return nil
+}
+
+type RemoveMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Commitment    *IdentityCommitment    `protobuf:"bytes,2,opt,name=commitment,proto3" json:"commitment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveMemberRequest) Reset() {
+	*x = RemoveMemberRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveMemberRequest) ProtoMessage() {}
+
+func (x *RemoveMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveMemberRequest.ProtoReflect.Descriptor instead.
+func (*RemoveMemberRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RemoveMemberRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *RemoveMemberRequest) GetCommitment() *IdentityCommitment {
+	if x != nil {
+		return x.Commitment
+	}
+	return nil
+}
+
+type MemberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Commitment    *IdentityCommitment    `protobuf:"bytes,2,opt,name=commitment,proto3" json:"commitment,omitempty"`
+	Root          []byte                 `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemberResponse) Reset() {
+	*x = MemberResponse{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberResponse) ProtoMessage() {}
+
+func (x *MemberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberResponse.ProtoReflect.Descriptor instead.
+func (*MemberResponse) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MemberResponse) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *MemberResponse) GetCommitment() *IdentityCommitment {
+	if x != nil {
+		return x.Commitment
+	}
+	return nil
+}
+
+func (x *MemberResponse) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+type GenerateMerkleProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Index         uint32                 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateMerkleProofRequest) Reset() {
+	*x = GenerateMerkleProofRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateMerkleProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateMerkleProofRequest) ProtoMessage() {}
+
+func (x *GenerateMerkleProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateMerkleProofRequest.ProtoReflect.Descriptor instead.
+func (*GenerateMerkleProofRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GenerateMerkleProofRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *GenerateMerkleProofRequest) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type VerifyProofRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	GroupId        string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	SemaphoreProof *SemaphoreProof        `protobuf:"bytes,2,opt,name=semaphore_proof,json=semaphoreProof,proto3" json:"semaphore_proof,omitempty"`
+	Proof          *Groth16Proof          `protobuf:"bytes,3,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VerifyProofRequest) Reset() {
+	*x = VerifyProofRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyProofRequest) ProtoMessage() {}
+
+func (x *VerifyProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyProofRequest.ProtoReflect.Descriptor instead.
+func (*VerifyProofRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *VerifyProofRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *VerifyProofRequest) GetSemaphoreProof() *SemaphoreProof {
+	if x != nil {
+		return x.SemaphoreProof
+	}
+	return nil
+}
+
+func (x *VerifyProofRequest) GetProof() *Groth16Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type VerifyProofResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Nullifier     []byte                 `protobuf:"bytes,2,opt,name=nullifier,proto3" json:"nullifier,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyProofResponse) Reset() {
+	*x = VerifyProofResponse{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyProofResponse) ProtoMessage() {}
+
+func (x *VerifyProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyProofResponse.ProtoReflect.Descriptor instead.
+func (*VerifyProofResponse) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *VerifyProofResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyProofResponse) GetNullifier() []byte {
+	if x != nil {
+		return x.Nullifier
+	}
+	return nil
+}
+
+type GetVerifyingKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVerifyingKeyRequest) Reset() {
+	*x = GetVerifyingKeyRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVerifyingKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVerifyingKeyRequest) ProtoMessage() {}
+
+func (x *GetVerifyingKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVerifyingKeyRequest.ProtoReflect.Descriptor instead.
+func (*GetVerifyingKeyRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{15}
+}
+
+type VerifyingKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"` // gnark serialized BN254 groth16 verifying key
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyingKey) Reset() {
+	*x = VerifyingKey{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyingKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyingKey) ProtoMessage() {}
+
+func (x *VerifyingKey) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyingKey.ProtoReflect.Descriptor instead.
+func (*VerifyingKey) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VerifyingKey) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type WatchGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchGroupRequest) Reset() {
+	*x = WatchGroupRequest{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchGroupRequest) ProtoMessage() {}
+
+func (x *WatchGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchGroupRequest.ProtoReflect.Descriptor instead.
+func (*WatchGroupRequest) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatchGroupRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+// GroupEvent describes a change of a group membership
+type GroupEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          GroupEvent_Type        `protobuf:"varint,1,opt,name=type,proto3,enum=semaphore.v1.GroupEvent_Type" json:"type,omitempty"`
+	Index         uint32                 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	OldCommitment *IdentityCommitment    `protobuf:"bytes,3,opt,name=old_commitment,json=oldCommitment,proto3" json:"old_commitment,omitempty"` // unset for added members
+	NewCommitment *IdentityCommitment    `protobuf:"bytes,4,opt,name=new_commitment,json=newCommitment,proto3" json:"new_commitment,omitempty"` // zero for removed members
+	Root          []byte                 `protobuf:"bytes,5,opt,name=root,proto3" json:"root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupEvent) Reset() {
+	*x = GroupEvent{}
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupEvent) ProtoMessage() {}
+
+func (x *GroupEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_semaphore_v1_semaphore_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupEvent.ProtoReflect.Descriptor instead.
+func (*GroupEvent) Descriptor() ([]byte, []int) {
+	return file_semaphore_v1_semaphore_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GroupEvent) GetType() GroupEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return GroupEvent_TYPE_UNSPECIFIED
+}
+
+func (x *GroupEvent) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GroupEvent) GetOldCommitment() *IdentityCommitment {
+	if x != nil {
+		return x.OldCommitment
+	}
+	return nil
+}
+
+func (x *GroupEvent) GetNewCommitment() *IdentityCommitment {
+	if x != nil {
+		return x.NewCommitment
+	}
+	return nil
+}
+
+func (x *GroupEvent) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+var File_semaphore_v1_semaphore_proto protoreflect.FileDescriptor
+
+const file_semaphore_v1_semaphore_proto_rawDesc = "" +
+	"\n" +
+	"\x1csemaphore/v1/semaphore.proto\x12\fsemaphore.v1\"*\n" +
+	"\x12IdentityCommitment\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\"{\n" +
+	"\vMerkleProof\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x12\n" +
+	"\x04leaf\x18\x02 \x01(\fR\x04leaf\x12\x12\n" +
+	"\x04root\x18\x03 \x01(\fR\x04root\x12\x12\n" +
+	"\x04path\x18\x04 \x03(\rR\x04path\x12\x1a\n" +
+	"\bsiblings\x18\x05 \x03(\fR\bsiblings\"V\n" +
+	"\x06Domain\x12\x15\n" +
+	"\x06app_id\x18\x01 \x01(\tR\x05appId\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x04R\achainId\x12\x1a\n" +
+	"\bverifier\x18\x03 \x01(\fR\bverifier\"\xad\x01\n" +
+	"\x0eSemaphoreProof\x12\x1f\n" +
+	"\vmerkle_root\x18\x01 \x01(\fR\n" +
+	"merkleRoot\x12\x1c\n" +
+	"\tnullifier\x18\x02 \x01(\fR\tnullifier\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\fR\amessage\x12\x14\n" +
+	"\x05scope\x18\x04 \x01(\fR\x05scope\x12,\n" +
+	"\x06domain\x18\x05 \x01(\v2\x14.semaphore.v1.DomainR\x06domain\"\"\n" +
+	"\fGroth16Proof\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\x8e\x01\n" +
+	"\x05Group\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\rR\x04size\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\rR\x05depth\x12\x12\n" +
+	"\x04root\x18\x04 \x01(\fR\x04root\x12,\n" +
+	"\x06domain\x18\x05 \x01(\v2\x14.semaphore.v1.DomainR\x06domain\"\x99\x01\n" +
+	"\x12CreateGroupRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12:\n" +
+	"\amembers\x18\x02 \x03(\v2 .semaphore.v1.IdentityCommitmentR\amembers\x12,\n" +
+	"\x06domain\x18\x03 \x01(\v2\x14.semaphore.v1.DomainR\x06domain\",\n" +
+	"\x0fGetGroupRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\"o\n" +
+	"\x10AddMemberRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12@\n" +
+	"\n" +
+	"commitment\x18\x02 \x01(\v2 .semaphore.v1.IdentityCommitmentR\n" +
+	"commitment\"\xc2\x01\n" +
+	"\x13UpdateMemberRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12G\n" +
+	"\x0eold_commitment\x18\x02 \x01(\v2 .semaphore.v1.IdentityCommitmentR\roldCommitment\x12G\n" +
+	"\x0enew_commitment\x18\x03 \x01(\v2 .semaphore.v1.IdentityCommitmentR\rnewCommitment\"r\n" +
+	"\x13RemoveMemberRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12@\n" +
+	"\n" +
+	"commitment\x18\x02 \x01(\v2 .semaphore.v1.IdentityCommitmentR\n" +
+	"commitment\"|\n" +
+	"\x0eMemberResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12@\n" +
+	"\n" +
+	"commitment\x18\x02 \x01(\v2 .semaphore.v1.IdentityCommitmentR\n" +
+	"commitment\x12\x12\n" +
+	"\x04root\x18\x03 \x01(\fR\x04root\"M\n" +
+	"\x1aGenerateMerkleProofRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\rR\x05index\"\xa8\x01\n" +
+	"\x12VerifyProofRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12E\n" +
+	"\x0fsemaphore_proof\x18\x02 \x01(\v2\x1c.semaphore.v1.SemaphoreProofR\x0esemaphoreProof\x120\n" +
+	"\x05proof\x18\x03 \x01(\v2\x1a.semaphore.v1.Groth16ProofR\x05proof\"I\n" +
+	"\x13VerifyProofResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x1c\n" +
+	"\tnullifier\x18\x02 \x01(\fR\tnullifier\"\x18\n" +
+	"\x16GetVerifyingKeyRequest\"\"\n" +
+	"\fVerifyingKey\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\".\n" +
+	"\x11WatchGroupRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\"\xe2\x02\n" +
+	"\n" +
+	"GroupEvent\x121\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1d.semaphore.v1.GroupEvent.TypeR\x04type\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\rR\x05index\x12G\n" +
+	"\x0eold_commitment\x18\x03 \x01(\v2 .semaphore.v1.IdentityCommitmentR\roldCommitment\x12G\n" +
+	"\x0enew_commitment\x18\x04 \x01(\v2 .semaphore.v1.IdentityCommitmentR\rnewCommitment\x12\x12\n" +
+	"\x04root\x18\x05 \x01(\fR\x04root\"e\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11TYPE_MEMBER_ADDED\x10\x01\x12\x17\n" +
+	"\x13TYPE_MEMBER_UPDATED\x10\x02\x12\x17\n" +
+	"\x13TYPE_MEMBER_REMOVED\x10\x032\xd5\x05\n" +
+	"\x10SemaphoreService\x12D\n" +
+	"\vCreateGroup\x12 .semaphore.v1.CreateGroupRequest\x1a\x13.semaphore.v1.Group\x12>\n" +
+	"\bGetGroup\x12\x1d.semaphore.v1.GetGroupRequest\x1a\x13.semaphore.v1.Group\x12I\n" +
+	"\tAddMember\x12\x1e.semaphore.v1.AddMemberRequest\x1a\x1c.semaphore.v1.MemberResponse\x12O\n" +
+	"\fUpdateMember\x12!.semaphore.v1.UpdateMemberRequest\x1a\x1c.semaphore.v1.MemberResponse\x12O\n" +
+	"\fRemoveMember\x12!.semaphore.v1.RemoveMemberRequest\x1a\x1c.semaphore.v1.MemberResponse\x12Z\n" +
+	"\x13GenerateMerkleProof\x12(.semaphore.v1.GenerateMerkleProofRequest\x1a\x19.semaphore.v1.MerkleProof\x12R\n" +
+	"\vVerifyProof\x12 .semaphore.v1.VerifyProofRequest\x1a!.semaphore.v1.VerifyProofResponse\x12S\n" +
+	"\x0fGetVerifyingKey\x12$.semaphore.v1.GetVerifyingKeyRequest\x1a\x1a.semaphore.v1.VerifyingKey\x12I\n" +
+	"\n" +
+	"WatchGroup\x12\x1f.semaphore.v1.WatchGroupRequest\x1a\x18.semaphore.v1.GroupEvent0\x01B<Z:github.com/NguyenHiu/semaphore-implementation-in-go/rpc/pbb\x06proto3"
+
+var (
+	file_semaphore_v1_semaphore_proto_rawDescOnce sync.Once
+	file_semaphore_v1_semaphore_proto_rawDescData []byte
+)
+
+func file_semaphore_v1_semaphore_proto_rawDescGZIP() []byte {
+	file_semaphore_v1_semaphore_proto_rawDescOnce.Do(func() {
+		file_semaphore_v1_semaphore_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_semaphore_v1_semaphore_proto_rawDesc), len(file_semaphore_v1_semaphore_proto_rawDesc)))
+	})
+	return file_semaphore_v1_semaphore_proto_rawDescData
+}
+
+var file_semaphore_v1_semaphore_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_semaphore_v1_semaphore_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_semaphore_v1_semaphore_proto_goTypes = []any{
+	(GroupEvent_Type)(0),               // 0: semaphore.v1.GroupEvent.Type
+	(*IdentityCommitment)(nil),         // 1: semaphore.v1.IdentityCommitment
+	(*MerkleProof)(nil),                // 2: semaphore.v1.MerkleProof
+	(*Domain)(nil),                     // 3: semaphore.v1.Domain
+	(*SemaphoreProof)(nil),             // 4: semaphore.v1.SemaphoreProof
+	(*Groth16Proof)(nil),               // 5: semaphore.v1.Groth16Proof
+	(*Group)(nil),                      // 6: semaphore.v1.Group
+	(*CreateGroupRequest)(nil),         // 7: semaphore.v1.CreateGroupRequest
+	(*GetGroupRequest)(nil),            // 8: semaphore.v1.GetGroupRequest
+	(*AddMemberRequest)(nil),           // 9: semaphore.v1.AddMemberRequest
+	(*UpdateMemberRequest)(nil),        // 10: semaphore.v1.UpdateMemberRequest
+	(*RemoveMemberRequest)(nil),        // 11: semaphore.v1.RemoveMemberRequest
+	(*MemberResponse)(nil),             // 12: semaphore.v1.MemberResponse
+	(*GenerateMerkleProofRequest)(nil), // 13: semaphore.v1.GenerateMerkleProofRequest
+	(*VerifyProofRequest)(nil),         // 14: semaphore.v1.VerifyProofRequest
+	(*VerifyProofResponse)(nil),        // 15: semaphore.v1.VerifyProofResponse
+	(*GetVerifyingKeyRequest)(nil),     // 16: semaphore.v1.GetVerifyingKeyRequest
+	(*VerifyingKey)(nil),               // 17: semaphore.v1.VerifyingKey
+	(*WatchGroupRequest)(nil),          // 18: semaphore.v1.WatchGroupRequest
+	(*GroupEvent)(nil),                 // 19: semaphore.v1.GroupEvent
+}
+var file_semaphore_v1_semaphore_proto_depIdxs = []int32{
+	3,  // 0: semaphore.v1.SemaphoreProof.domain:type_name -> semaphore.v1.Domain
+	3,  // 1: semaphore.v1.Group.domain:type_name -> semaphore.v1.Domain
+	1,  // 2: semaphore.v1.CreateGroupRequest.members:type_name -> semaphore.v1.IdentityCommitment
+	3,  // 3: semaphore.v1.CreateGroupRequest.domain:type_name -> semaphore.v1.Domain
+	1,  // 4: semaphore.v1.AddMemberRequest.commitment:type_name -> semaphore.v1.IdentityCommitment
+	1,  // 5: semaphore.v1.UpdateMemberRequest.old_commitment:type_name -> semaphore.v1.IdentityCommitment
+	1,  // 6: semaphore.v1.UpdateMemberRequest.new_commitment:type_name -> semaphore.v1.IdentityCommitment
+	1,  // 7: semaphore.v1.RemoveMemberRequest.commitment:type_name -> semaphore.v1.IdentityCommitment
+	1,  // 8: semaphore.v1.MemberResponse.commitment:type_name -> semaphore.v1.IdentityCommitment
+	4,  // 9: semaphore.v1.VerifyProofRequest.semaphore_proof:type_name -> semaphore.v1.SemaphoreProof
+	5,  // 10: semaphore.v1.VerifyProofRequest.proof:type_name -> semaphore.v1.Groth16Proof
+	0,  // 11: semaphore.v1.GroupEvent.type:type_name -> semaphore.v1.GroupEvent.Type
+	1,  // 12: semaphore.v1.GroupEvent.old_commitment:type_name -> semaphore.v1.IdentityCommitment
+	1,  // 13: semaphore.v1.GroupEvent.new_commitment:type_name -> semaphore.v1.IdentityCommitment
+	7,  // 14: semaphore.v1.SemaphoreService.CreateGroup:input_type -> semaphore.v1.CreateGroupRequest
+	8,  // 15: semaphore.v1.SemaphoreService.GetGroup:input_type -> semaphore.v1.GetGroupRequest
+	9,  // 16: semaphore.v1.SemaphoreService.AddMember:input_type -> semaphore.v1.AddMemberRequest
+	10, // 17: semaphore.v1.SemaphoreService.UpdateMember:input_type -> semaphore.v1.UpdateMemberRequest
+	11, // 18: semaphore.v1.SemaphoreService.RemoveMember:input_type -> semaphore.v1.RemoveMemberRequest
+	13, // 19: semaphore.v1.SemaphoreService.GenerateMerkleProof:input_type -> semaphore.v1.GenerateMerkleProofRequest
+	14, // 20: semaphore.v1.SemaphoreService.VerifyProof:input_type -> semaphore.v1.VerifyProofRequest
+	16, // 21: semaphore.v1.SemaphoreService.GetVerifyingKey:input_type -> semaphore.v1.GetVerifyingKeyRequest
+	18, // 22: semaphore.v1.SemaphoreService.WatchGroup:input_type -> semaphore.v1.WatchGroupRequest
+	6,  // 23: semaphore.v1.SemaphoreService.CreateGroup:output_type -> semaphore.v1.Group
+	6,  // 24: semaphore.v1.SemaphoreService.GetGroup:output_type -> semaphore.v1.Group
+	12, // 25: semaphore.v1.SemaphoreService.AddMember:output_type -> semaphore.v1.MemberResponse
+	12, // 26: semaphore.v1.SemaphoreService.UpdateMember:output_type -> semaphore.v1.MemberResponse
+	12, // 27: semaphore.v1.SemaphoreService.RemoveMember:output_type -> semaphore.v1.MemberResponse
+	2,  // 28: semaphore.v1.SemaphoreService.GenerateMerkleProof:output_type -> semaphore.v1.MerkleProof
+	15, // 29: semaphore.v1.SemaphoreService.VerifyProof:output_type -> semaphore.v1.VerifyProofResponse
+	17, // 30: semaphore.v1.SemaphoreService.GetVerifyingKey:output_type -> semaphore.v1.VerifyingKey
+	19, // 31: semaphore.v1.SemaphoreService.WatchGroup:output_type -> semaphore.v1.GroupEvent
+	23, // [23:32] is the sub-list for method output_type
+	14, // [14:23] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_semaphore_v1_semaphore_proto_init() }
+func file_semaphore_v1_semaphore_proto_init() {
+	if File_semaphore_v1_semaphore_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_semaphore_v1_semaphore_proto_rawDesc), len(file_semaphore_v1_semaphore_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_semaphore_v1_semaphore_proto_goTypes,
+		DependencyIndexes: file_semaphore_v1_semaphore_proto_depIdxs,
+		EnumInfos:         file_semaphore_v1_semaphore_proto_enumTypes,
+		MessageInfos:      file_semaphore_v1_semaphore_proto_msgTypes,
+	}.Build()
+	File_semaphore_v1_semaphore_proto = out.File
+	file_semaphore_v1_semaphore_proto_goTypes = nil
+	file_semaphore_v1_semaphore_proto_depIdxs = nil
+}
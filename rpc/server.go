@@ -0,0 +1,445 @@
+// Package rpc exposes semaphore groups and proof verification as a gRPC service,
+// the protobuf schema is in proto/semaphore/v1/semaphore.proto
+package rpc
+
+//go:generate sh -c "cd ../proto && buf generate"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/rpc/pb"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WATCH_BUFFER_SIZE is the number of events buffered for each watcher,
+// slower watchers are disconnected
+const WATCH_BUFFER_SIZE = 64
+
+// group is a semaphore group guarded by its own lock, with its event watchers
+type group struct {
+	mu       sync.Mutex
+	s        *semaphore.Semaphore
+	watchers map[chan *pb.GroupEvent]bool
+}
+
+// Server implements the SemaphoreService gRPC service.
+// All groups share the same circuit and keys
+type Server struct {
+	pb.UnimplementedSemaphoreServiceServer
+
+	mu     sync.RWMutex
+	groups map[string]*group
+	ccs    constraint.ConstraintSystem
+	pk     groth16.ProvingKey
+	vk     groth16.VerifyingKey
+}
+
+// NewServer returns a new gRPC server using an already setup Semaphore circuit
+func NewServer(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey) *Server {
+	return &Server{
+		groups: make(map[string]*group),
+		ccs:    ccs,
+		pk:     pk,
+		vk:     vk,
+	}
+}
+
+// CreateGroup creates a new group with optional initial members
+func (srv *Server) CreateGroup(ctx context.Context, req *pb.CreateGroupRequest) (*pb.Group, error) {
+	if req.GroupId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing group id")
+	}
+
+	s := semaphore.NewSemaphoreWithKeys(srv.ccs, srv.pk, srv.vk)
+	if req.Domain != nil {
+		if err := s.SetDomain(parseDomain(req.Domain)); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	for _, m := range req.Members {
+		idc, err := parseCommitment(m)
+		if err != nil {
+			return nil, err
+		}
+		if s.IndexOf(idc) != -1 {
+			return nil, status.Errorf(codes.InvalidArgument, "duplicated member %s", idc)
+		}
+		if err := s.AddMember(idc); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if _, ok := srv.groups[req.GroupId]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "group %q already exists", req.GroupId)
+	}
+	srv.groups[req.GroupId] = &group{s: s, watchers: make(map[chan *pb.GroupEvent]bool)}
+	return groupMessage(req.GroupId, s), nil
+}
+
+// GetGroup returns the size, depth and root of a group
+func (srv *Server) GetGroup(ctx context.Context, req *pb.GetGroupRequest) (*pb.Group, error) {
+	g, err := srv.lockGroup(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+	defer g.mu.Unlock()
+	return groupMessage(req.GroupId, g.s), nil
+}
+
+// AddMember inserts an identity commitment into a group
+func (srv *Server) AddMember(ctx context.Context, req *pb.AddMemberRequest) (*pb.MemberResponse, error) {
+	idc, err := parseCommitment(req.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	g, err := srv.lockGroup(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+	defer g.mu.Unlock()
+
+	if g.s.IndexOf(idc) != -1 {
+		return nil, status.Error(codes.AlreadyExists, "the provided identity commitment is already a member")
+	}
+	if err := g.s.AddMember(idc); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := memberResponse(g.s, g.s.IndexOf(idc), idc)
+	g.publish(&pb.GroupEvent{
+		Type:          pb.GroupEvent_TYPE_MEMBER_ADDED,
+		Index:         res.Index,
+		NewCommitment: res.Commitment,
+		Root:          res.Root,
+	})
+	return res, nil
+}
+
+// UpdateMember replaces an identity commitment of a group by a new one
+func (srv *Server) UpdateMember(ctx context.Context, req *pb.UpdateMemberRequest) (*pb.MemberResponse, error) {
+	oldIdc, err := parseCommitment(req.OldCommitment)
+	if err != nil {
+		return nil, err
+	}
+	newIdc, err := parseCommitment(req.NewCommitment)
+	if err != nil {
+		return nil, err
+	}
+	g, err := srv.lockGroup(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+	defer g.mu.Unlock()
+
+	idx := g.s.IndexOf(oldIdc)
+	if idx == -1 {
+		return nil, status.Error(codes.NotFound, "the provided identity commitment doesn't exist")
+	}
+	if g.s.IndexOf(newIdc) != -1 {
+		return nil, status.Error(codes.AlreadyExists, "the new identity commitment is already a member")
+	}
+	if err := g.s.UpdateMember(oldIdc, newIdc); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := memberResponse(g.s, idx, newIdc)
+	g.publish(&pb.GroupEvent{
+		Type:          pb.GroupEvent_TYPE_MEMBER_UPDATED,
+		Index:         res.Index,
+		OldCommitment: req.OldCommitment,
+		NewCommitment: res.Commitment,
+		Root:          res.Root,
+	})
+	return res, nil
+}
+
+// RemoveMember removes an identity commitment from a group
+func (srv *Server) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.MemberResponse, error) {
+	idc, err := parseCommitment(req.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	g, err := srv.lockGroup(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+	defer g.mu.Unlock()
+
+	idx := g.s.IndexOf(idc)
+	if idx == -1 {
+		return nil, status.Error(codes.NotFound, "the provided identity commitment doesn't exist")
+	}
+	if err := g.s.RemoveMember(idc, nil); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := memberResponse(g.s, idx, big.NewInt(0))
+	g.publish(&pb.GroupEvent{
+		Type:          pb.GroupEvent_TYPE_MEMBER_REMOVED,
+		Index:         res.Index,
+		OldCommitment: req.Commitment,
+		NewCommitment: res.Commitment,
+		Root:          res.Root,
+	})
+	return res, nil
+}
+
+// GenerateMerkleProof returns the merkle proof of a group member
+func (srv *Server) GenerateMerkleProof(ctx context.Context, req *pb.GenerateMerkleProofRequest) (*pb.MerkleProof, error) {
+	g, err := srv.lockGroup(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+	defer g.mu.Unlock()
+
+	merkleProof, err := g.s.GenerateMerkleProof(int(req.Index))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	res := &pb.MerkleProof{
+		Index: req.Index,
+		Leaf:  merkleProof.Node.Bytes(),
+		Root:  merkleProof.Root.Bytes(),
+	}
+	for i := range merkleProof.Path {
+		res.Path = append(res.Path, uint32(merkleProof.Path[i]))
+		res.Siblings = append(res.Siblings, merkleProof.Siblings[i].Bytes())
+	}
+	return res, nil
+}
+
+// VerifyProof verifies a semaphore proof against a group and marks its nullifier as used
+func (srv *Server) VerifyProof(ctx context.Context, req *pb.VerifyProofRequest) (*pb.VerifyProofResponse, error) {
+	sProof, err := parseSemaphoreProof(req.SemaphoreProof)
+	if err != nil {
+		return nil, err
+	}
+	if req.Proof == nil || len(req.Proof.Data) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing proof")
+	}
+	proof := new(groth16_bn254.Proof)
+	if _, err := proof.ReadFrom(bytes.NewReader(req.Proof.Data)); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid proof: %v", err)
+	}
+
+	g, err := srv.lockGroup(req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+	defer g.mu.Unlock()
+
+	if err := g.s.VerifyProof(proof, sProof); err != nil {
+		return nil, status.Error(verificationCode(err), err.Error())
+	}
+	return &pb.VerifyProofResponse{Valid: true, Nullifier: sProof.Nullifier.Bytes()}, nil
+}
+
+// GetVerifyingKey returns the serialized groth16 verifying key
+func (srv *Server) GetVerifyingKey(ctx context.Context, req *pb.GetVerifyingKeyRequest) (*pb.VerifyingKey, error) {
+	var buf bytes.Buffer
+	if _, err := srv.vk.WriteTo(&buf); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.VerifyingKey{Data: buf.Bytes()}, nil
+}
+
+// WatchGroup streams the membership events of a group until the client disconnects
+func (srv *Server) WatchGroup(req *pb.WatchGroupRequest, stream pb.SemaphoreService_WatchGroupServer) error {
+	g, err := srv.lockGroup(req.GroupId)
+	if err != nil {
+		return err
+	}
+	events := make(chan *pb.GroupEvent, WATCH_BUFFER_SIZE)
+	g.watchers[events] = true
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		if g.watchers[events] {
+			delete(g.watchers, events)
+		}
+		g.mu.Unlock()
+	}()
+
+	// Let the client know that it is subscribed
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "too slow to consume group events")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// lockGroup returns the locked group of `id`
+func (srv *Server) lockGroup(id string) (*group, error) {
+	srv.mu.RLock()
+	g, ok := srv.groups[id]
+	srv.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "group %q doesn't exist", id)
+	}
+	g.mu.Lock()
+	return g, nil
+}
+
+// publish sends an event to all the watchers of the group, the group must be locked
+func (g *group) publish(event *pb.GroupEvent) {
+	for events := range g.watchers {
+		select {
+		case events <- event:
+		default:
+			// Disconnect watchers whose buffer is full
+			delete(g.watchers, events)
+			close(events)
+		}
+	}
+}
+
+// groupMessage describes the current state of a group
+func groupMessage(id string, s *semaphore.Semaphore) *pb.Group {
+	imt := s.GetGroup()
+	res := &pb.Group{GroupId: id, Size: uint32(imt.Size()), Domain: DomainMessage(s.GetDomain())}
+	if imt.Size() != 0 {
+		res.Depth = uint32(imt.Depth())
+		res.Root = imt.Root().Bytes()
+	}
+	return res
+}
+
+// memberResponse describes a member of a group and the current group root
+func memberResponse(s *semaphore.Semaphore, idx int, idc *big.Int) *pb.MemberResponse {
+	return &pb.MemberResponse{
+		Index:      uint32(idx),
+		Commitment: &pb.IdentityCommitment{Value: idc.Bytes()},
+		Root:       s.GetGroup().Root().Bytes(),
+	}
+}
+
+// parseField parses a big-endian BN254 scalar field element
+func parseField(name string, b []byte) (*big.Int, error) {
+	if len(b) > 32 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is not a field element", name)
+	}
+	v := new(big.Int).SetBytes(b)
+	if v.Cmp(ecc.BN254.ScalarField()) >= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is not a field element", name)
+	}
+	return v, nil
+}
+
+// verificationCode maps a verification error to its status code,
+// replayed nullifiers are reported separately from invalid proofs
+func verificationCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, semaphore.ErrNullifierUsed):
+		return codes.AlreadyExists
+	case errors.Is(err, semaphore.ErrInvalidMessage),
+		errors.Is(err, semaphore.ErrInvalidScope),
+		errors.Is(err, semaphore.ErrInvalidMerkleRoot),
+		errors.Is(err, semaphore.ErrInvalidProof),
+		errors.Is(err, semaphore.ErrStaleProof),
+		errors.Is(err, semaphore.ErrWrongDomain),
+		errors.Is(err, semaphore.ErrInvalidNullifierInput):
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
+
+// parseCommitment parses an identity commitment, which can't be zero
+// since zero leaves are used for removed members
+func parseCommitment(idc *pb.IdentityCommitment) (*big.Int, error) {
+	if idc == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing commitment")
+	}
+	v, err := parseField("commitment", idc.Value)
+	if err != nil {
+		return nil, err
+	}
+	if v.Sign() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "commitment can't be zero")
+	}
+	return v, nil
+}
+
+// parseSemaphoreProof converts a protobuf semaphore proof
+func parseSemaphoreProof(p *pb.SemaphoreProof) (semaphore.SemaphoreProof, error) {
+	var sProof semaphore.SemaphoreProof
+	if p == nil {
+		return sProof, status.Error(codes.InvalidArgument, "missing semaphore proof")
+	}
+	var err error
+	if sProof.MerkleRoot, err = parseField("merkle root", p.MerkleRoot); err != nil {
+		return sProof, err
+	}
+	if sProof.Nullifier, err = parseField("nullifier", p.Nullifier); err != nil {
+		return sProof, err
+	}
+	if sProof.Message, err = parseField("message", p.Message); err != nil {
+		return sProof, err
+	}
+	if sProof.Scope, err = parseField("scope", p.Scope); err != nil {
+		return sProof, err
+	}
+	if p.Domain != nil {
+		domain := parseDomain(p.Domain)
+		sProof.Domain = &domain
+	}
+	return sProof, nil
+}
+
+// parseDomain converts a protobuf domain
+func parseDomain(d *pb.Domain) semaphore.Domain {
+	return semaphore.Domain{AppID: d.AppId, ChainID: d.ChainId, Verifier: d.Verifier}
+}
+
+// DomainMessage converts a domain into its protobuf message, nil if unbound
+func DomainMessage(domain *semaphore.Domain) *pb.Domain {
+	if domain == nil {
+		return nil
+	}
+	return &pb.Domain{AppId: domain.AppID, ChainId: domain.ChainID, Verifier: domain.Verifier}
+}
+
+// SemaphoreProofMessage converts a semaphore proof into its protobuf message
+func SemaphoreProofMessage(sProof semaphore.SemaphoreProof) *pb.SemaphoreProof {
+	return &pb.SemaphoreProof{
+		MerkleRoot: sProof.MerkleRoot.Bytes(),
+		Nullifier:  sProof.Nullifier.Bytes(),
+		Message:    sProof.Message.Bytes(),
+		Scope:      sProof.Scope.Bytes(),
+		Domain:     DomainMessage(sProof.Domain),
+	}
+}
+
+// Groth16ProofMessage converts a groth16 proof into its protobuf message
+func Groth16ProofMessage(proof *groth16_bn254.Proof) (*pb.Groth16Proof, error) {
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return &pb.Groth16Proof{Data: buf.Bytes()}, nil
+}
@@ -0,0 +1,215 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"math/rand/v2"
+	"net"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/rpc/pb"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// requireCode checks that the gRPC error has the expected status code
+func requireCode(t *testing.T, code codes.Code, err error) {
+	require.Error(t, err)
+	require.Equal(t, code, status.Code(err))
+}
+
+// commitment wraps a big integer into an identity commitment message
+func commitment(v *big.Int) *pb.IdentityCommitment {
+	return &pb.IdentityCommitment{Value: v.Bytes()}
+}
+
+// TestServer runs the group management, event streaming and proof verification
+// flow against an in-process gRPC server
+func TestServer(t *testing.T) {
+	ccs, pk, vk, err := semaphore.SetupCircuit()
+	require.NoError(t, err)
+
+	// Serve over an in-memory listener
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterSemaphoreServiceServer(srv, NewServer(ccs, pk, vk))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewSemaphoreServiceClient(conn)
+	ctx := context.Background()
+
+	// Create a group
+	_, err = client.CreateGroup(ctx, &pb.CreateGroupRequest{GroupId: "g"})
+	require.NoError(t, err)
+	_, err = client.CreateGroup(ctx, &pb.CreateGroupRequest{GroupId: "g"})
+	requireCode(t, codes.AlreadyExists, err)
+	_, err = client.GetGroup(ctx, &pb.GetGroupRequest{GroupId: "unknown"})
+	requireCode(t, codes.NotFound, err)
+
+	// Watch the group events
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.WatchGroup(watchCtx, &pb.WatchGroupRequest{GroupId: "g"})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	// Add members
+	n := 5
+	secrets := []*big.Int{}
+	idcs := []*big.Int{}
+	for i := 0; i < n; i++ {
+		id, err := identity.New()
+		require.NoError(t, err)
+		secret, idc := id.Secret(), id.Commitment()
+		secrets = append(secrets, secret)
+		idcs = append(idcs, idc)
+
+		res, err := client.AddMember(ctx, &pb.AddMemberRequest{GroupId: "g", Commitment: commitment(idc)})
+		require.NoError(t, err)
+		require.Equal(t, uint32(i), res.Index)
+
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, pb.GroupEvent_TYPE_MEMBER_ADDED, event.Type)
+		require.Equal(t, uint32(i), event.Index)
+		require.Equal(t, idc.Bytes(), event.NewCommitment.Value)
+		require.Equal(t, res.Root, event.Root)
+	}
+	_, err = client.AddMember(ctx, &pb.AddMemberRequest{GroupId: "g", Commitment: commitment(idcs[0])})
+	requireCode(t, codes.AlreadyExists, err)
+	_, err = client.AddMember(ctx, &pb.AddMemberRequest{GroupId: "g", Commitment: commitment(big.NewInt(0))})
+	requireCode(t, codes.InvalidArgument, err)
+
+	// Update and remove members
+	newID, err := identity.New()
+	require.NoError(t, err)
+	newSecret, newIdc := newID.Secret(), newID.Commitment()
+	_, err = client.UpdateMember(ctx, &pb.UpdateMemberRequest{GroupId: "g", OldCommitment: commitment(idcs[1]), NewCommitment: commitment(newIdc)})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, pb.GroupEvent_TYPE_MEMBER_UPDATED, event.Type)
+	require.Equal(t, idcs[1].Bytes(), event.OldCommitment.Value)
+	secrets[1], idcs[1] = newSecret, newIdc
+
+	_, err = client.RemoveMember(ctx, &pb.RemoveMemberRequest{GroupId: "g", Commitment: commitment(idcs[4])})
+	require.NoError(t, err)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, pb.GroupEvent_TYPE_MEMBER_REMOVED, event.Type)
+	require.Equal(t, uint32(4), event.Index)
+	_, err = client.RemoveMember(ctx, &pb.RemoveMemberRequest{GroupId: "g", Commitment: commitment(idcs[4])})
+	requireCode(t, codes.NotFound, err)
+
+	// Fetch the merkle proof of a random member
+	idx := rand.IntN(n - 1)
+	mp, err := client.GenerateMerkleProof(ctx, &pb.GenerateMerkleProofRequest{GroupId: "g", Index: uint32(idx)})
+	require.NoError(t, err)
+	require.Equal(t, event.Root, mp.Root)
+	_, err = client.GenerateMerkleProof(ctx, &pb.GenerateMerkleProofRequest{GroupId: "g", Index: uint32(n)})
+	requireCode(t, codes.NotFound, err)
+
+	// Generate the semaphore proof on the client side
+	merkleProof := leanIMT.MerkleProof{
+		Node: new(big.Int).SetBytes(mp.Leaf),
+		Root: new(big.Int).SetBytes(mp.Root),
+	}
+	for i := range mp.Path {
+		merkleProof.Path = append(merkleProof.Path, int(mp.Path[i]))
+		merkleProof.Siblings = append(merkleProof.Siblings, new(big.Int).SetBytes(mp.Siblings[i]))
+	}
+	scope := big.NewInt(rand.Int64N(1000))
+	nullifier, err := semaphore.MimcHash([]*big.Int{scope, secrets[idx]})
+	require.NoError(t, err)
+	sProof := semaphore.SemaphoreProof{
+		MerkleRoot: merkleProof.Root,
+		Nullifier:  nullifier,
+		Message:    big.NewInt(rand.Int64N(1000)),
+		Scope:      scope,
+	}
+	proof, err := semaphore.GenerateSemaphoreProof(ccs, pk, secrets[idx], merkleProof, sProof)
+	require.NoError(t, err)
+	proofMsg, err := Groth16ProofMessage(proof)
+	require.NoError(t, err)
+
+	// Invalid proofs are rejected
+	badSProof := sProof
+	badSProof.Message = big.NewInt(1001)
+	_, err = client.VerifyProof(ctx, &pb.VerifyProofRequest{GroupId: "g", SemaphoreProof: SemaphoreProofMessage(badSProof), Proof: proofMsg})
+	requireCode(t, codes.FailedPrecondition, err)
+	_, err = client.VerifyProof(ctx, &pb.VerifyProofRequest{GroupId: "g", SemaphoreProof: SemaphoreProofMessage(sProof)})
+	requireCode(t, codes.InvalidArgument, err)
+
+	// Verify the proof, then replay it
+	res, err := client.VerifyProof(ctx, &pb.VerifyProofRequest{GroupId: "g", SemaphoreProof: SemaphoreProofMessage(sProof), Proof: proofMsg})
+	require.NoError(t, err)
+	require.True(t, res.Valid)
+	_, err = client.VerifyProof(ctx, &pb.VerifyProofRequest{GroupId: "g", SemaphoreProof: SemaphoreProofMessage(sProof), Proof: proofMsg})
+	requireCode(t, codes.AlreadyExists, err)
+
+	// A group bound to a domain only accepts the proofs of its domain
+	domain := &pb.Domain{AppId: "poll", ChainId: 1, Verifier: []byte{0xaa}}
+	members := []*pb.IdentityCommitment{}
+	for _, idc := range idcs[:4] {
+		members = append(members, commitment(idc))
+	}
+	_, err = client.CreateGroup(ctx, &pb.CreateGroupRequest{GroupId: "d", Domain: &pb.Domain{ChainId: 1}})
+	requireCode(t, codes.InvalidArgument, err)
+	group, err := client.CreateGroup(ctx, &pb.CreateGroupRequest{GroupId: "d", Members: members, Domain: domain})
+	require.NoError(t, err)
+	require.Equal(t, "poll", group.Domain.AppId)
+	mp, err = client.GenerateMerkleProof(ctx, &pb.GenerateMerkleProofRequest{GroupId: "d", Index: uint32(idx)})
+	require.NoError(t, err)
+	merkleProof = leanIMT.MerkleProof{
+		Node: new(big.Int).SetBytes(mp.Leaf),
+		Root: new(big.Int).SetBytes(mp.Root),
+	}
+	for i := range mp.Path {
+		merkleProof.Path = append(merkleProof.Path, int(mp.Path[i]))
+		merkleProof.Siblings = append(merkleProof.Siblings, new(big.Int).SetBytes(mp.Siblings[i]))
+	}
+	sProof.Domain = &semaphore.Domain{AppID: "poll", ChainID: 1, Verifier: []byte{0xaa}}
+	boundScope, err := sProof.Domain.Scope(scope)
+	require.NoError(t, err)
+	sProof.MerkleRoot = merkleProof.Root
+	sProof.Nullifier, err = semaphore.MimcHash([]*big.Int{boundScope, secrets[idx]})
+	require.NoError(t, err)
+	proof, err = semaphore.GenerateSemaphoreProof(ccs, pk, secrets[idx], merkleProof, sProof)
+	require.NoError(t, err)
+	proofMsg, err = Groth16ProofMessage(proof)
+	require.NoError(t, err)
+	badSProof = sProof
+	badSProof.Domain = nil
+	_, err = client.VerifyProof(ctx, &pb.VerifyProofRequest{GroupId: "d", SemaphoreProof: SemaphoreProofMessage(badSProof), Proof: proofMsg})
+	requireCode(t, codes.FailedPrecondition, err)
+	_, err = client.VerifyProof(ctx, &pb.VerifyProofRequest{GroupId: "g", SemaphoreProof: SemaphoreProofMessage(sProof), Proof: proofMsg})
+	requireCode(t, codes.FailedPrecondition, err)
+	res, err = client.VerifyProof(ctx, &pb.VerifyProofRequest{GroupId: "d", SemaphoreProof: SemaphoreProofMessage(sProof), Proof: proofMsg})
+	require.NoError(t, err)
+	require.True(t, res.Valid)
+
+	// Fetch the verifying key
+	vkMsg, err := client.GetVerifyingKey(ctx, &pb.GetVerifyingKeyRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, vkMsg.Data)
+
+	// Stop watching
+	cancel()
+	_, err = stream.Recv()
+	requireCode(t, codes.Canceled, err)
+}
@@ -0,0 +1,160 @@
+package semaphore
+
+import (
+	"fmt"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// NewAggregationCircuit returns the circuit aggregating `size` proofs of the Semaphore
+// circuit `ccs` and its verifying key `vk`. Each proof costs about 1.6M constraints.
+// Only the proofs of the Semaphore circuit can be aggregated, the verifying keys of the
+// nullifier modes and of the timestamped circuit have other public inputs
+func NewAggregationCircuit(ccs constraint.ConstraintSystem, vk groth16.VerifyingKey, size int) (*circuits.Aggregation, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("the aggregation needs at least 1 proof")
+	}
+	bvk, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return nil, fmt.Errorf("the verifying key isn't a BN254 key")
+	}
+	// K has a point for the constant 1 and for each public input
+	if n := len(bvk.G1.K) - 1; n != circuits.INNER_PUBLIC_INPUTS {
+		return nil, fmt.Errorf("only the Semaphore circuit can be aggregated, the verifying key has %d public inputs instead of %d",
+			n, circuits.INNER_PUBLIC_INPUTS)
+	}
+	innerVk, err := stdgroth16.ValueOfVerifyingKeyFixed[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](vk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the verifying key: %w", err)
+	}
+	circuit := &circuits.Aggregation{
+		VerifyingKey: innerVk,
+		Proofs:       make([]circuits.InnerProof, size),
+		Messages:     make([]frontend.Variable, size),
+		Scopes:       make([]frontend.Variable, size),
+		MerkleRoots:  make([]frontend.Variable, size),
+		Nullifiers:   make([]frontend.Variable, size),
+	}
+	for i := range circuit.Proofs {
+		circuit.Proofs[i] = stdgroth16.PlaceholderProof[sw_bn254.G1Affine, sw_bn254.G2Affine](ccs)
+	}
+	return circuit, nil
+}
+
+// SetupAggregation performs the setup phase of the circuit aggregating `size`
+// proofs of the Semaphore circuit `ccs` and its verifying key `vk`
+func SetupAggregation(ccs constraint.ConstraintSystem, vk groth16.VerifyingKey, size int) (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	circuit, err := NewAggregationCircuit(ccs, vk, size)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	aggCcs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to compile circuit: %w", err)
+	}
+	// Should perform MPC!
+	pk, aggVk, err := groth16.Setup(aggCcs)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to setup circuit: %w", err)
+	}
+	return aggCcs, pk, aggVk, nil
+}
+
+// AggregationAssignment returns the assignment of the aggregation of semaphore proofs,
+// the verifying key isn't part of the assignment since it is a constant of the circuit
+func AggregationAssignment(proofs []*groth16_bn254.Proof, sProofs []SemaphoreProof) (*circuits.Aggregation, error) {
+	if len(proofs) != len(sProofs) {
+		return nil, fmt.Errorf("len(proofs) != len(sProofs)")
+	}
+	assignment, err := aggregationPublic(sProofs)
+	if err != nil {
+		return nil, err
+	}
+	for i, proof := range proofs {
+		if proof == nil {
+			return nil, fmt.Errorf("missing proof %d", i)
+		}
+		p, err := stdgroth16.ValueOfProof[sw_bn254.G1Affine, sw_bn254.G2Affine](proof)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert proof %d: %w", i, err)
+		}
+		assignment.Proofs[i] = p
+	}
+	return assignment, nil
+}
+
+// AggregateProofs generates a single groth16 proof of a batch of semaphore proofs,
+// the batch must have the size of the aggregation circuit `aggCcs`
+func AggregateProofs(
+	aggCcs constraint.ConstraintSystem,
+	aggPk groth16.ProvingKey,
+	proofs []*groth16_bn254.Proof,
+	sProofs []SemaphoreProof,
+) (*groth16_bn254.Proof, error) {
+	assignment, err := AggregationAssignment(proofs, sProofs)
+	if err != nil {
+		return nil, err
+	}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate witness: %v", err)
+	}
+	return proveWitness(aggCcs, aggPk, witness)
+}
+
+// VerifyAggregatedProof returns nil if the aggregated proof proves all the semaphore proofs
+// `sProofs`. It only checks the proofs against the merkle roots they carry: the callers still
+// have to check that each root is a root of their group, as Semaphore.VerifyProof does, and
+// the nullifiers against the used ones
+func VerifyAggregatedProof(aggVk groth16.VerifyingKey, proof *groth16_bn254.Proof, sProofs []SemaphoreProof) error {
+	for _, sProof := range sProofs {
+		if err := checkSignals(sProof); err != nil {
+			return err
+		}
+	}
+	assignment, err := aggregationPublic(sProofs)
+	if err != nil {
+		return newVerificationError(ReasonInvalidScope, err)
+	}
+	pubWit, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("failed to create public witness: %w", err))
+	}
+	if err := groth16.Verify(proof, aggVk, pubWit); err != nil {
+		return newVerificationError(ReasonInvalidProof, err)
+	}
+	return nil
+}
+
+// aggregationPublic returns the public inputs of the aggregation of semaphore proofs, the scopes
+// are bound to the domains of the proofs. The proofs are only allocated, so that gnark doesn't warn about them
+func aggregationPublic(sProofs []SemaphoreProof) (*circuits.Aggregation, error) {
+	assignment := &circuits.Aggregation{Proofs: make([]circuits.InnerProof, len(sProofs))}
+	for _, sProof := range sProofs {
+		if sProof.NullifierMode != NULLIFIER_PER_SCOPE || sProof.Timestamp != nil {
+			return nil, fmt.Errorf("only the proofs of the Semaphore circuit can be aggregated")
+		}
+		scope, err := circuitScope(sProof)
+		if err != nil {
+			return nil, err
+		}
+		assignment.Messages = append(assignment.Messages, sProof.Message)
+		assignment.Scopes = append(assignment.Scopes, scope)
+		assignment.MerkleRoots = append(assignment.MerkleRoots, sProof.MerkleRoot)
+		assignment.Nullifiers = append(assignment.Nullifiers, sProof.Nullifier)
+	}
+	return assignment, nil
+}
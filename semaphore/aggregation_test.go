@@ -0,0 +1,72 @@
+package semaphore
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAggregationCircuit checks the aggregation circuit with the test engine,
+// which solves the constraints without the costly setup
+func TestAggregationCircuit(t *testing.T) {
+	s, proofs, sProofs := newTestProofs(t, 2, 3)
+	circuit, err := NewAggregationCircuit(s.GetCss(), s.GetVerifyingKey(), 2)
+	require.NoError(t, err)
+	assignment, err := AggregationAssignment(proofs, sProofs)
+	require.NoError(t, err)
+	require.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// Tampered public inputs and swapped proofs aren't solved
+	badSProofs := append([]SemaphoreProof{}, sProofs...)
+	badSProofs[1].Nullifier = new(big.Int).Add(sProofs[1].Nullifier, big.NewInt(1))
+	assignment, err = AggregationAssignment(proofs, badSProofs)
+	require.NoError(t, err)
+	require.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	assignment, err = AggregationAssignment([]*groth16_bn254.Proof{proofs[1], proofs[0]}, sProofs)
+	require.NoError(t, err)
+	require.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	_, err = AggregationAssignment(proofs[1:], sProofs)
+	require.Error(t, err)
+}
+
+// TestAggregateProofs checks the aggregation of a single proof. The circuit is always solved with
+// the test engine, but the setup of the aggregation circuit takes several minutes per core, so the
+// prover and the verifier only run if SEMAPHORE_AGGREGATION_TEST is set, with a larger -timeout
+func TestAggregateProofs(t *testing.T) {
+	s, proofs, sProofs := newTestProofs(t, 1, 3)
+	circuit, err := NewAggregationCircuit(s.GetCss(), s.GetVerifyingKey(), 1)
+	require.NoError(t, err)
+	assignment, err := AggregationAssignment(proofs, sProofs)
+	require.NoError(t, err)
+	require.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// Only the proofs of the Semaphore circuit can be aggregated
+	_, _, epochVk, err := SetupNullifierCircuit(NULLIFIER_PER_EPOCH)
+	require.NoError(t, err)
+	_, err = NewAggregationCircuit(s.GetCss(), epochVk, 1)
+	require.ErrorContains(t, err, "only the Semaphore circuit can be aggregated")
+	timestamped := sProofs[0]
+	timestamped.Timestamp = Timestamp(time.Now())
+	_, err = AggregationAssignment(proofs, []SemaphoreProof{timestamped})
+	require.Error(t, err)
+
+	if os.Getenv("SEMAPHORE_AGGREGATION_TEST") == "" {
+		t.Skip("SEMAPHORE_AGGREGATION_TEST isn't set")
+	}
+	aggCcs, aggPk, aggVk, err := SetupAggregation(s.GetCss(), s.GetVerifyingKey(), 1)
+	require.NoError(t, err)
+	proof, err := AggregateProofs(aggCcs, aggPk, proofs, sProofs)
+	require.NoError(t, err)
+	require.NoError(t, VerifyAggregatedProof(aggVk, proof, sProofs))
+
+	badSProofs := append([]SemaphoreProof{}, sProofs...)
+	badSProofs[0].MerkleRoot = new(big.Int).Add(sProofs[0].MerkleRoot, big.NewInt(1))
+	require.ErrorIs(t, VerifyAggregatedProof(aggVk, proof, badSProofs), ErrInvalidProof)
+}
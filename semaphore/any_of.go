@@ -0,0 +1,130 @@
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// NB_ANY_OF_PUBLIC is the number of public inputs of the SemaphoreAnyOf circuit besides its roots
+const NB_ANY_OF_PUBLIC = 4
+
+// SetupAnyOfCircuit performs the setup phase of the Semaphore circuit accepting up to `k` groups
+func SetupAnyOfCircuit(k int) (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	if k < 1 {
+		return nil, nil, nil, fmt.Errorf("the circuit needs at least 1 root")
+	}
+	return setupCircuitContext(context.Background(), &circuits.SemaphoreAnyOf{MerkleRoots: make([]frontend.Variable, k)})
+}
+
+// GenerateAnyOfProof returns the groth16 proof of a member of one of the groups of root `roots`,
+// the merkle proof must be a proof of one of them. The MerkleRoot of `sProof` is ignored
+func GenerateAnyOfProof(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	secret *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	sProof SemaphoreProof,
+	roots []*big.Int,
+) (*groth16_bn254.Proof, error) {
+	// The constraint system counts the constant 1 as a public variable
+	padded, err := padRoots(roots, ccs.GetNbPublicVariables()-1-NB_ANY_OF_PUBLIC)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, root := range roots {
+		found = found || (root != nil && merkleProof.Root != nil && root.Cmp(merkleProof.Root) == 0)
+	}
+	if !found {
+		return nil, fmt.Errorf("the root of the merkle proof isn't one of the roots")
+	}
+
+	sProof.MerkleRoot = merkleProof.Root
+	s, err := semaphoreAssignment(secret, merkleProof, sProof)
+	if err != nil {
+		return nil, err
+	}
+	assignment := &circuits.SemaphoreAnyOf{
+		Secret:              s.Secret,
+		MerkleProofLength:   s.MerkleProofLength,
+		MerkleProofIndices:  s.MerkleProofIndices,
+		MerkleProofSiblings: s.MerkleProofSiblings,
+		Message:             s.Message,
+		Scope:               s.Scope,
+		DummySquare:         s.DummySquare,
+		MerkleRoots:         padded,
+		Nullifier:           s.Nullifier,
+	}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate witness: %v", err)
+	}
+	return proveWitness(ccs, pk, witness)
+}
+
+// VerifyAnyOfProof returns nil if the proof is correct and was generated by a member of one
+// of the groups of root `roots`. The MerkleRoot of `sProof` is ignored
+func VerifyAnyOfProof(
+	vk groth16.VerifyingKey,
+	proof *groth16_bn254.Proof,
+	sProof SemaphoreProof,
+	roots []*big.Int,
+) error {
+	if err := checkSignals(sProof); err != nil {
+		return err
+	}
+	padded, err := padRoots(roots, vk.NbPublicWitness()-NB_ANY_OF_PUBLIC)
+	if err != nil {
+		return newVerificationError(ReasonInvalidMerkleRoot, err)
+	}
+	scope, err := circuitScope(sProof)
+	if err != nil {
+		return newVerificationError(ReasonInvalidScope, err)
+	}
+	assignment := &circuits.SemaphoreAnyOf{
+		Message:     sProof.Message,
+		Scope:       scope,
+		DummySquare: new(big.Int).Mul(sProof.Message, sProof.Message),
+		MerkleRoots: padded,
+		Nullifier:   sProof.Nullifier,
+	}
+	pubWit, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("failed to create public witness: %w", err))
+	}
+	if err := groth16.Verify(proof, vk, pubWit); err != nil {
+		return newVerificationError(ReasonInvalidProof, err)
+	}
+	return nil
+}
+
+// padRoots returns the `k` public roots of the circuit, the roots are padded
+// with the last one so that a circuit accepts fewer groups than its size
+func padRoots(roots []*big.Int, k int) ([]frontend.Variable, error) {
+	if len(roots) == 0 || len(roots) > k {
+		return nil, fmt.Errorf("the number of roots must be between 1 and %d", k)
+	}
+	padded := make([]frontend.Variable, k)
+	for i := range padded {
+		root := roots[min(i, len(roots)-1)]
+		if root == nil {
+			return nil, fmt.Errorf("missing root %d", i)
+		}
+		padded[i] = root
+	}
+	return padded, nil
+}
@@ -0,0 +1,46 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAnyOfProof checks proofs of membership in one of several groups
+func TestAnyOfProof(t *testing.T) {
+	ccs, pk, vk, err := SetupAnyOfCircuit(4)
+	require.NoError(t, err)
+
+	// 3 groups of 3 members
+	groups := []*Semaphore{}
+	secrets := [][]*big.Int{}
+	roots := []*big.Int{}
+	for i := 0; i < 3; i++ {
+		s := newTestSemaphore(t)
+		groupSecrets, idcs := newTestMembers(t, 3)
+		require.NoError(t, s.AddMembers(idcs))
+		groups = append(groups, s)
+		secrets = append(secrets, groupSecrets)
+		roots = append(roots, s.GetGroup().Root())
+	}
+
+	// A member of the second group
+	merkleProof, err := groups[1].GenerateMerkleProof(2)
+	require.NoError(t, err)
+	sProof := randomSemaphoreProof(roots[1], secrets[1][2], t)
+	proof, err := GenerateAnyOfProof(ccs, pk, secrets[1][2], merkleProof, sProof, roots)
+	require.NoError(t, err)
+	require.NoError(t, VerifyAnyOfProof(vk, proof, sProof, roots))
+
+	// The proof doesn't hold for other roots or inputs
+	require.ErrorIs(t, VerifyAnyOfProof(vk, proof, sProof, []*big.Int{roots[0], roots[2]}), ErrInvalidProof)
+	badSProof := sProof
+	badSProof.Scope = new(big.Int).Add(sProof.Scope, big.NewInt(1))
+	require.ErrorIs(t, VerifyAnyOfProof(vk, proof, badSProof, roots), ErrInvalidProof)
+	require.ErrorIs(t, VerifyAnyOfProof(vk, proof, sProof, append(roots, roots[0], roots[1])), ErrInvalidMerkleRoot)
+
+	// The merkle proof must be a proof of one of the roots
+	_, err = GenerateAnyOfProof(ccs, pk, secrets[1][2], merkleProof, sProof, []*big.Int{roots[0], roots[2]})
+	require.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// AttributePredicate is the public range of the hidden attribute, both bounds included
+type AttributePredicate struct {
+	Min *big.Int
+	Max *big.Int
+}
+
+// AttributeEqual returns the predicate of an attribute equal to `value`, e.g. an admin role
+func AttributeEqual(value *big.Int) AttributePredicate {
+	return AttributePredicate{Min: value, Max: value}
+}
+
+// AttributeLeaf returns the leaf of a member with an attribute, to be added to the group instead
+// of its identity commitment. Attributes that aren't numbers, such as roles, can be mapped
+// to field elements with HashToField
+func AttributeLeaf(idc, attribute *big.Int) (*big.Int, error) {
+	return MimcHash([]*big.Int{idc, attribute})
+}
+
+// SetupAttributeCircuit performs the setup phase of the Semaphore circuit with attributes
+func SetupAttributeCircuit() (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	return setupCircuitContext(context.Background(), &circuits.SemaphoreAttribute{})
+}
+
+// GenerateAttributeProof returns the groth16 proof of a member whose attribute satisfies
+// the predicate, the merkle proof is the proof of its attribute leaf
+func GenerateAttributeProof(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	secret *big.Int,
+	attribute *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	sProof SemaphoreProof,
+	predicate AttributePredicate,
+) (*groth16_bn254.Proof, error) {
+	if attribute == nil || predicate.Min == nil || predicate.Max == nil {
+		return nil, fmt.Errorf("missing attribute or predicate")
+	}
+	if attribute.Cmp(predicate.Min) < 0 || attribute.Cmp(predicate.Max) > 0 {
+		return nil, fmt.Errorf("the attribute doesn't satisfy the predicate")
+	}
+	s, err := semaphoreAssignment(secret, merkleProof, sProof)
+	if err != nil {
+		return nil, err
+	}
+	assignment := &circuits.SemaphoreAttribute{
+		Secret:              s.Secret,
+		Attribute:           attribute,
+		MerkleProofLength:   s.MerkleProofLength,
+		MerkleProofIndices:  s.MerkleProofIndices,
+		MerkleProofSiblings: s.MerkleProofSiblings,
+		Message:             s.Message,
+		Scope:               s.Scope,
+		DummySquare:         s.DummySquare,
+		MerkleRoot:          s.MerkleRoot,
+		Nullifier:           s.Nullifier,
+		AttributeMin:        predicate.Min,
+		AttributeMax:        predicate.Max,
+	}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate witness: %v", err)
+	}
+	return proveWitness(ccs, pk, witness)
+}
+
+// VerifyAttributeProof returns nil if the proof is correct and the attribute
+// of the member satisfies the predicate
+func VerifyAttributeProof(
+	vk groth16.VerifyingKey,
+	proof *groth16_bn254.Proof,
+	sProof SemaphoreProof,
+	predicate AttributePredicate,
+) error {
+	if predicate.Min == nil || predicate.Max == nil {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("missing predicate"))
+	}
+	if err := checkSignals(sProof); err != nil {
+		return err
+	}
+	scope, err := circuitScope(sProof)
+	if err != nil {
+		return newVerificationError(ReasonInvalidScope, err)
+	}
+	assignment := &circuits.SemaphoreAttribute{
+		Message:      sProof.Message,
+		Scope:        scope,
+		DummySquare:  new(big.Int).Mul(sProof.Message, sProof.Message),
+		MerkleRoot:   sProof.MerkleRoot,
+		Nullifier:    sProof.Nullifier,
+		AttributeMin: predicate.Min,
+		AttributeMax: predicate.Max,
+	}
+	pubWit, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("failed to create public witness: %w", err))
+	}
+	if err := groth16.Verify(proof, vk, pubWit); err != nil {
+		return newVerificationError(ReasonInvalidProof, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAttributeProof checks range and equality predicates on the hidden attributes
+func TestAttributeProof(t *testing.T) {
+	ccs, pk, vk, err := SetupAttributeCircuit()
+	require.NoError(t, err)
+
+	// Members with their age, and a member with a role
+	s := newTestSemaphore(t)
+	secrets, idcs := newTestMembers(t, 4)
+	admin := HashToField([]byte("admin"))
+	attributes := []*big.Int{big.NewInt(16), big.NewInt(30), big.NewInt(65), admin}
+	for i, idc := range idcs {
+		leaf, err := AttributeLeaf(idc, attributes[i])
+		require.NoError(t, err)
+		require.NoError(t, s.AddMember(leaf))
+	}
+
+	adult := AttributePredicate{Min: big.NewInt(18), Max: big.NewInt(200)}
+	prove := func(i int, predicate AttributePredicate) (*SemaphoreProof, error) {
+		merkleProof, err := s.GenerateMerkleProof(i)
+		require.NoError(t, err)
+		sProof := randomSemaphoreProof(s.GetGroup().Root(), secrets[i], t)
+		proof, err := GenerateAttributeProof(ccs, pk, secrets[i], attributes[i], merkleProof, sProof, predicate)
+		if err != nil {
+			return nil, err
+		}
+		require.NoError(t, VerifyAttributeProof(vk, proof, sProof, predicate))
+		require.ErrorIs(t, VerifyAttributeProof(vk, proof, sProof, AttributePredicate{Min: big.NewInt(40), Max: big.NewInt(200)}), ErrInvalidProof)
+		return &sProof, nil
+	}
+
+	// Range predicates
+	_, err = prove(1, adult)
+	require.NoError(t, err)
+	_, err = prove(2, adult)
+	require.NoError(t, err)
+	_, err = prove(0, adult)
+	require.Error(t, err)
+
+	// Equality predicate
+	_, err = prove(3, AttributeEqual(admin))
+	require.NoError(t, err)
+	_, err = prove(1, AttributeEqual(admin))
+	require.Error(t, err)
+
+	// The circuit rejects a member lying about its attribute
+	merkleProof, err := s.GenerateMerkleProof(0)
+	require.NoError(t, err)
+	sProof := randomSemaphoreProof(s.GetGroup().Root(), secrets[0], t)
+	assignment, err := semaphoreAssignment(secrets[0], merkleProof, sProof)
+	require.NoError(t, err)
+	lying := &circuits.SemaphoreAttribute{
+		Secret:              assignment.Secret,
+		MerkleProofLength:   assignment.MerkleProofLength,
+		MerkleProofIndices:  assignment.MerkleProofIndices,
+		MerkleProofSiblings: assignment.MerkleProofSiblings,
+		Message:             assignment.Message,
+		Scope:               assignment.Scope,
+		DummySquare:         assignment.DummySquare,
+		MerkleRoot:          assignment.MerkleRoot,
+		Nullifier:           assignment.Nullifier,
+		AttributeMin:        adult.Min,
+		AttributeMax:        adult.Max,
+	}
+	for _, attribute := range []*big.Int{attributes[0], big.NewInt(18)} {
+		lying.Attribute = attribute
+		require.Error(t, test.IsSolved(&circuits.SemaphoreAttribute{}, lying, ecc.BN254.ScalarField()))
+	}
+	lying.Attribute = big.NewInt(16)
+	lying.AttributeMin = big.NewInt(16)
+	require.NoError(t, test.IsSolved(&circuits.SemaphoreAttribute{}, lying, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,193 @@
+package semaphore
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// BATCH_RANDOMNESS_BITS is the size of the random coefficients of the batch verification,
+// a batch containing an invalid proof passes with probability 2^-BATCH_RANDOMNESS_BITS
+const BATCH_RANDOMNESS_BITS = 128
+
+// BatchVerifySemaphoreProofs verifies many semaphore proofs against one verifying key,
+// and returns the indices of the invalid proofs (nil if all the proofs are valid).
+// The error is only set if the inputs can't be verified at all.
+//
+// Each groth16 proof satisfies e(A, B) = e(α, β)·e(K, γ)·e(C, δ), with K the
+// combination of the verifying key and the public inputs. The proofs are combined
+// with random coefficients rᵢ into a single check:
+//
+//	∏ e(rᵢ·Aᵢ, Bᵢ) · e(-Σ rᵢ·Kᵢ, γ) · e(-Σ rᵢ·Cᵢ, δ) · e(-(Σ rᵢ)·α, β) = 1
+//
+// which costs n+3 miller loops and a single final exponentiation instead of
+// 4 pairings per proof. If the check fails, the proofs are verified one by one
+// to identify the invalid ones
+func BatchVerifySemaphoreProofs(
+	vk groth16.VerifyingKey,
+	proofs []*groth16_bn254.Proof,
+	sProofs []SemaphoreProof,
+) ([]int, error) {
+	if len(proofs) != len(sProofs) {
+		return nil, fmt.Errorf("len(proofs) != len(sProofs)")
+	}
+	bvk, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return nil, fmt.Errorf("the verifying key isn't a bn254 verifying key")
+	}
+	if len(bvk.CommitmentKeys) != 0 {
+		return nil, fmt.Errorf("batch verification doesn't support commitments")
+	}
+	nbPublic := len(bvk.G1.K) - 1
+
+	// Proofs with malformed points or public inputs are invalid without further checks
+	invalid := []int{}
+	batch := []int{}
+	publicInputs := make([][]fr.Element, len(proofs))
+	for i := range proofs {
+		if proofs[i] == nil || len(proofs[i].Commitments) != 0 || checkProofPoints(proofs[i]) != nil {
+			invalid = append(invalid, i)
+			continue
+		}
+		inputs, err := publicInputsOf(sProofs[i])
+		if err != nil || len(inputs) != nbPublic {
+			invalid = append(invalid, i)
+			continue
+		}
+		publicInputs[i] = inputs
+		batch = append(batch, i)
+	}
+
+	if len(batch) != 0 {
+		ok, err := batchCheck(bvk, proofs, publicInputs, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		// Find the offenders
+		if !ok {
+			for _, i := range batch {
+				if err := VerifySemaphoreProof(vk, proofs[i], sProofs[i]); err != nil {
+					invalid = append(invalid, i)
+				}
+			}
+		}
+	}
+
+	if len(invalid) == 0 {
+		return nil, nil
+	}
+	slices.Sort(invalid)
+	return invalid, nil
+}
+
+// batchCheck runs the random linear combination check on the proofs at `batch` indices
+func batchCheck(
+	vk *groth16_bn254.VerifyingKey,
+	proofs []*groth16_bn254.Proof,
+	publicInputs [][]fr.Element,
+	batch []int,
+) (bool, error) {
+	n := len(batch)
+	nbPublic := len(vk.G1.K) - 1
+
+	// Random coefficients
+	bound := new(big.Int).Lsh(big.NewInt(1), BATCH_RANDOMNESS_BITS)
+	r := make([]fr.Element, n)
+	for i := range r {
+		v, err := rand.Int(rand.Reader, bound)
+		if err != nil {
+			return false, err
+		}
+		r[i].SetBigInt(v)
+	}
+
+	// Σ rᵢ·Kᵢ = (Σ rᵢ)·K₀ + Σⱼ (Σᵢ rᵢ·xᵢⱼ)·Kⱼ₊₁
+	kCoeffs := make([]fr.Element, nbPublic+1)
+	for i, idx := range batch {
+		kCoeffs[0].Add(&kCoeffs[0], &r[i])
+		for j := 0; j < nbPublic; j++ {
+			var t fr.Element
+			t.Mul(&r[i], &publicInputs[idx][j])
+			kCoeffs[j+1].Add(&kCoeffs[j+1], &t)
+		}
+	}
+	var kSum bn254.G1Affine
+	if _, err := kSum.MultiExp(vk.G1.K, kCoeffs, ecc.MultiExpConfig{}); err != nil {
+		return false, err
+	}
+
+	// Σ rᵢ·Cᵢ
+	cs := make([]bn254.G1Affine, n)
+	for i, idx := range batch {
+		cs[i] = proofs[idx].Krs
+	}
+	var cSum bn254.G1Affine
+	if _, err := cSum.MultiExp(cs, r, ecc.MultiExpConfig{}); err != nil {
+		return false, err
+	}
+
+	// (Σ rᵢ)·α
+	var rSum big.Int
+	kCoeffs[0].BigInt(&rSum)
+	var alpha bn254.G1Affine
+	alpha.ScalarMultiplication(&vk.G1.Alpha, &rSum)
+
+	// Pairing inputs
+	P := make([]bn254.G1Affine, 0, n+3)
+	Q := make([]bn254.G2Affine, 0, n+3)
+	for i, idx := range batch {
+		var rA bn254.G1Affine
+		var ri big.Int
+		r[i].BigInt(&ri)
+		rA.ScalarMultiplication(&proofs[idx].Ar, &ri)
+		P = append(P, rA)
+		Q = append(Q, proofs[idx].Bs)
+	}
+	kSum.Neg(&kSum)
+	cSum.Neg(&cSum)
+	alpha.Neg(&alpha)
+	P = append(P, kSum, cSum, alpha)
+	Q = append(Q, vk.G2.Gamma, vk.G2.Delta, vk.G2.Beta)
+
+	return bn254.PairingCheck(P, Q)
+}
+
+// publicInputsOf returns the public inputs of the Semaphore circuit for a semaphore proof,
+// in the order of the circuit: message, scope, dummy square, merkle root, nullifier,
+// then the input of the nullifier or the timestamp, if any
+func publicInputsOf(sProof SemaphoreProof) ([]fr.Element, error) {
+	if sProof.Message == nil || sProof.Scope == nil || sProof.MerkleRoot == nil || sProof.Nullifier == nil {
+		return nil, fmt.Errorf("incomplete semaphore proof")
+	}
+	if err := checkSignals(sProof); err != nil {
+		return nil, err
+	}
+	if sProof.NullifierMode != NULLIFIER_PER_SCOPE && sProof.NullifierInput == nil {
+		return nil, fmt.Errorf("the %v nullifier needs an input", sProof.NullifierMode)
+	}
+	scope, err := circuitScope(sProof)
+	if err != nil {
+		return nil, err
+	}
+	dummySquare := new(big.Int).Mul(sProof.Message, sProof.Message)
+	vals := []*big.Int{sProof.Message, scope, dummySquare, sProof.MerkleRoot, sProof.Nullifier}
+	if sProof.NullifierMode != NULLIFIER_PER_SCOPE {
+		vals = append(vals, sProof.NullifierInput)
+	}
+	if sProof.Timestamp != nil {
+		vals = append(vals, sProof.Timestamp)
+	}
+	res := make([]fr.Element, len(vals))
+	for i := range vals {
+		res[i].SetBigInt(vals[i])
+	}
+	return res, nil
+}
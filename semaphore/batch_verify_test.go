@@ -0,0 +1,109 @@
+package semaphore
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProofs generates `n` proofs of random members of a group of `m` members,
+// each proof signals a different message in a different scope
+func newTestProofs(t testing.TB, n, m int) (*Semaphore, []*groth16_bn254.Proof, []SemaphoreProof) {
+	s := newTestSemaphore(t)
+	secrets := []*big.Int{}
+	for i := 0; i < m; i++ {
+		secret := big.NewInt(int64(i + 1))
+		idc, err := MimcHash([]*big.Int{secret})
+		require.NoError(t, err)
+		require.NoError(t, s.AddMember(idc))
+		secrets = append(secrets, secret)
+	}
+
+	proofs := []*groth16_bn254.Proof{}
+	sProofs := []SemaphoreProof{}
+	for i := 0; i < n; i++ {
+		idx := rand.IntN(m)
+		scope := big.NewInt(int64(i))
+		nullifier, err := MimcHash([]*big.Int{scope, secrets[idx]})
+		require.NoError(t, err)
+		sProof := SemaphoreProof{
+			MerkleRoot: s.group.Root(),
+			Nullifier:  nullifier,
+			Message:    randomBigInt(),
+			Scope:      scope,
+		}
+		merkleProof, err := s.GenerateMerkleProof(idx)
+		require.NoError(t, err)
+		proof, err := GenerateSemaphoreProof(s.GetCss(), s.GetProvingKey(), secrets[idx], merkleProof, sProof)
+		require.NoError(t, err)
+		proofs = append(proofs, proof)
+		sProofs = append(sProofs, sProof)
+	}
+	return s, proofs, sProofs
+}
+
+// TestBatchVerifySemaphoreProofs checks that valid batches pass and that
+// invalid proofs are identified
+func TestBatchVerifySemaphoreProofs(t *testing.T) {
+	n := 8
+	s, proofs, sProofs := newTestProofs(t, n, 5)
+	vk := s.GetVerifyingKey()
+
+	// All valid
+	invalid, err := BatchVerifySemaphoreProofs(vk, proofs, sProofs)
+	require.NoError(t, err)
+	require.Nil(t, invalid)
+
+	// Empty batch
+	invalid, err = BatchVerifySemaphoreProofs(vk, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, invalid)
+
+	// Tamper with public inputs and proofs
+	badSProofs := append([]SemaphoreProof{}, sProofs...)
+	badSProofs[1].Message = new(big.Int).Add(sProofs[1].Message, big.NewInt(1))
+	badProofs := append([]*groth16_bn254.Proof{}, proofs...)
+	badProofs[5] = proofs[6]
+	badProofs[7] = nil
+	invalid, err = BatchVerifySemaphoreProofs(vk, badProofs, badSProofs)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 5, 7}, invalid)
+
+	// Malformed inputs
+	_, err = BatchVerifySemaphoreProofs(vk, proofs[1:], sProofs)
+	require.Error(t, err)
+}
+
+// BenchmarkVerifySemaphoreProof verifies proofs one by one
+func BenchmarkVerifySemaphoreProof(b *testing.B) {
+	s, proofs, sProofs := newTestProofs(b, 64, 5)
+	vk := s.GetVerifyingKey()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range proofs {
+			if err := VerifySemaphoreProof(vk, proofs[j], sProofs[j]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkBatchVerifySemaphoreProofs verifies batches of proofs
+func BenchmarkBatchVerifySemaphoreProofs(b *testing.B) {
+	s, proofs, sProofs := newTestProofs(b, 64, 5)
+	vk := s.GetVerifyingKey()
+	for _, n := range []int{1, 8, 64} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				invalid, err := BatchVerifySemaphoreProofs(vk, proofs[:n], sProofs[:n])
+				if err != nil || invalid != nil {
+					b.Fatal(invalid, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/indexedMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// NewBlocklist returns an empty blocklist, the indexed merkle tree of the banned identity commitments
+func NewBlocklist() (*indexedMT.IndexedMT, error) {
+	return indexedMT.NewIndexedMT(MimcHash, circuits.INDEXED_DEPTH)
+}
+
+// SetupBlocklistCircuit performs the setup phase of the Semaphore circuit with a blocklist
+func SetupBlocklistCircuit() (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	return setupCircuitContext(context.Background(), &circuits.SemaphoreBlocklist{})
+}
+
+// GenerateBlocklistProof returns the groth16 proof of a member of the group which isn't
+// in the blocklist, `nonMembership` is the proof that its identity commitment isn't blocked
+func GenerateBlocklistProof(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	secret *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	nonMembership indexedMT.NonMembershipProof,
+	sProof SemaphoreProof,
+) (*groth16_bn254.Proof, error) {
+	s, err := semaphoreAssignment(secret, merkleProof, sProof)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonMembership.Path) != circuits.INDEXED_DEPTH || len(nonMembership.Siblings) != circuits.INDEXED_DEPTH {
+		return nil, fmt.Errorf("the blocklist proof must have a depth of %d", circuits.INDEXED_DEPTH)
+	}
+	assignment := &circuits.SemaphoreBlocklist{
+		Secret:                s.Secret,
+		MerkleProofLength:     s.MerkleProofLength,
+		MerkleProofIndices:    s.MerkleProofIndices,
+		MerkleProofSiblings:   s.MerkleProofSiblings,
+		BlocklistLowValue:     nonMembership.LowLeaf.Value,
+		BlocklistLowNextIndex: nonMembership.LowLeaf.NextIndex,
+		BlocklistLowNextValue: nonMembership.LowLeaf.NextValue,
+		Message:               s.Message,
+		Scope:                 s.Scope,
+		DummySquare:           s.DummySquare,
+		MerkleRoot:            s.MerkleRoot,
+		Nullifier:             s.Nullifier,
+		BlocklistRoot:         nonMembership.Root,
+	}
+	for i := 0; i < circuits.INDEXED_DEPTH; i++ {
+		assignment.BlocklistIndices[i] = nonMembership.Path[i]
+		assignment.BlocklistSiblings[i] = nonMembership.Siblings[i]
+	}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate witness: %v", err)
+	}
+	return proveWitness(ccs, pk, witness)
+}
+
+// VerifyBlocklistProof returns nil if the proof is correct and the member
+// isn't in the blocklist of root `blocklistRoot`
+func VerifyBlocklistProof(
+	vk groth16.VerifyingKey,
+	proof *groth16_bn254.Proof,
+	sProof SemaphoreProof,
+	blocklistRoot *big.Int,
+) error {
+	if err := checkSignals(sProof); err != nil {
+		return err
+	}
+	scope, err := circuitScope(sProof)
+	if err != nil {
+		return newVerificationError(ReasonInvalidScope, err)
+	}
+	assignment := &circuits.SemaphoreBlocklist{
+		Message:       sProof.Message,
+		Scope:         scope,
+		DummySquare:   new(big.Int).Mul(sProof.Message, sProof.Message),
+		MerkleRoot:    sProof.MerkleRoot,
+		Nullifier:     sProof.Nullifier,
+		BlocklistRoot: blocklistRoot,
+	}
+	pubWit, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("failed to create public witness: %w", err))
+	}
+	if err := groth16.Verify(proof, vk, pubWit); err != nil {
+		return newVerificationError(ReasonInvalidProof, err)
+	}
+	return nil
+}
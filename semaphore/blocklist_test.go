@@ -0,0 +1,55 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/indexedMT"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBlocklistProof checks that members prove they aren't blocked, and that
+// blocked members can't
+func TestBlocklistProof(t *testing.T) {
+	ccs, pk, vk, err := SetupBlocklistCircuit()
+	require.NoError(t, err)
+
+	s := newTestSemaphore(t)
+	secrets, idcs := newTestMembers(t, 4)
+	require.NoError(t, s.AddMembers(idcs))
+	blocklist, err := NewBlocklist()
+	require.NoError(t, err)
+	for _, idc := range []*big.Int{idcs[1], randomBigInt(), randomBigInt()} {
+		require.NoError(t, blocklist.Insert(idc))
+	}
+
+	prove := func(i int, nonMembership indexedMT.NonMembershipProof) (SemaphoreProof, error) {
+		merkleProof, err := s.GenerateMerkleProof(i)
+		require.NoError(t, err)
+		sProof := randomSemaphoreProof(s.GetGroup().Root(), secrets[i], t)
+		proof, err := GenerateBlocklistProof(ccs, pk, secrets[i], merkleProof, nonMembership, sProof)
+		if err != nil {
+			return sProof, err
+		}
+		require.NoError(t, VerifyBlocklistProof(vk, proof, sProof, blocklist.Root()))
+		otherRoot := new(big.Int).Add(blocklist.Root(), big.NewInt(1))
+		require.ErrorIs(t, VerifyBlocklistProof(vk, proof, sProof, otherRoot), ErrInvalidProof)
+		return sProof, nil
+	}
+
+	// A member which isn't blocked
+	nonMembership, err := blocklist.GenerateNonMembershipProof(idcs[0])
+	require.NoError(t, err)
+	_, err = prove(0, nonMembership)
+	require.NoError(t, err)
+
+	// A blocked member has no non-membership proof, and can't use the proof of another member
+	_, err = blocklist.GenerateNonMembershipProof(idcs[1])
+	require.Error(t, err)
+	_, err = prove(1, nonMembership)
+	require.Error(t, err)
+	forged := nonMembership
+	forged.LowLeaf = blocklist.Leaves[blocklist.Size()]
+	_, err = prove(1, forged)
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package semaphore
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
 	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
@@ -20,20 +22,51 @@ func SetupCircuit() (
 	groth16.ProvingKey,
 	groth16.VerifyingKey,
 	error,
+) {
+	return SetupCircuitContext(context.Background())
+}
+
+// SetupCircuitContext performs the setup phase of the Semaphore circuit,
+// and returns early if `ctx` is done before the compilation or the setup completes
+func SetupCircuitContext(ctx context.Context) (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	return setupCircuitContext(ctx, &circuits.Semaphore{})
+}
+
+// setupCircuitContext performs the setup phase of `circuit`, the Semaphore circuit
+// or one of its variants
+func setupCircuitContext(ctx context.Context, circuit frontend.Circuit) (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
 ) {
 	// Compile the circuit
-	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuits.Semaphore{})
+	ccs, err := runContext(ctx, func() (constraint.ConstraintSystem, error) {
+		return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	})
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to compile circuit: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to compile circuit: %w", err)
 	}
 
 	// Should perform MPC!
-	pk, vk, err := groth16.Setup(ccs)
+	type keys struct {
+		pk groth16.ProvingKey
+		vk groth16.VerifyingKey
+	}
+	k, err := runContext(ctx, func() (keys, error) {
+		pk, vk, err := groth16.Setup(ccs)
+		return keys{pk, vk}, err
+	})
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to setup circuit: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to setup circuit: %w", err)
 	}
 
-	return ccs, pk, vk, nil
+	return ccs, k.pk, k.vk, nil
 }
 
 // GenerateSemaphoreProof returns groth16 proof generated by the
@@ -45,7 +78,97 @@ func GenerateSemaphoreProof(
 	merkleProof leanIMT.MerkleProof,
 	sProof SemaphoreProof,
 ) (*groth16_bn254.Proof, error) {
+	return GenerateSemaphoreProofContext(context.Background(), ccs, pk, secret, merkleProof, sProof)
+}
+
+// GenerateSemaphoreProofContext returns groth16 proof like GenerateSemaphoreProof,
+// and returns early if `ctx` is done before the proof is generated
+func GenerateSemaphoreProofContext(
+	ctx context.Context,
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	secret *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	sProof SemaphoreProof,
+) (*groth16_bn254.Proof, error) {
+	if err := ctx.Err(); err != nil {
+		return &groth16_bn254.Proof{}, err
+	}
+	witness, err := NewSemaphoreWitness(secret, merkleProof, sProof)
+	if err != nil {
+		return &groth16_bn254.Proof{}, err
+	}
+	proof, err := runContext(ctx, func() (*groth16_bn254.Proof, error) {
+		return proveWitness(ccs, pk, witness)
+	})
+	if err != nil {
+		return &groth16_bn254.Proof{}, err
+	}
+	return proof, nil
+}
+
+// runContext runs an expensive gnark call, and returns early with the error of `ctx`
+// if it is done first. gnark calls can't be interrupted, so an abandoned call
+// keeps running in the background until it completes
+func runContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// NewSemaphoreWitness returns the full witness of the circuit of the semaphore proof,
+// see circuitAssignment, for the provided private signals (secret, merkle proof) and semaphore proof
+func NewSemaphoreWitness(
+	secret *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	sProof SemaphoreProof,
+) (witness.Witness, error) {
+	s, err := semaphoreAssignment(secret, merkleProof, sProof)
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := circuitAssignment(s, sProof)
+	if err != nil {
+		return nil, err
+	}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate witness: %v", err)
+	}
+	return witness, nil
+}
+
+// semaphoreAssignment returns the assignment of the Semaphore circuit, also
+// used to build the assignments of the variants of the circuit
+func semaphoreAssignment(
+	secret *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	sProof SemaphoreProof,
+) (*circuits.Semaphore, error) {
 	// Calculate circuit inputs
+	if err := checkMerkleProof(merkleProof); err != nil {
+		return nil, err
+	}
 	ml := len(merkleProof.Path)
 	merkleLen := frontend.Variable(ml)
 	merkleIndices := [MAX_DEPTH]frontend.Variable{}
@@ -60,6 +183,10 @@ func GenerateSemaphoreProof(
 		}
 	}
 	dummySquare := new(big.Int).Mul(sProof.Message, sProof.Message)
+	scope, err := circuitScope(sProof)
+	if err != nil {
+		return nil, err
+	}
 
 	// Init a circuit assignment to generate witness
 	assignment := &circuits.Semaphore{
@@ -68,48 +195,135 @@ func GenerateSemaphoreProof(
 		MerkleProofIndices:  merkleIndices,
 		MerkleProofSiblings: merkleSiblings,
 		Message:             sProof.Message,
-		Scope:               sProof.Scope,
+		Scope:               scope,
 		DummySquare:         dummySquare,
 		MerkleRoot:          merkleProof.Root,
 		Nullifier:           sProof.Nullifier,
 	}
-	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
-	if err != nil {
-		return &groth16_bn254.Proof{}, fmt.Errorf("failed to calculate witness: %v", err)
+	return assignment, nil
+}
+
+// checkMerkleProof returns an error if the merkle proof doesn't fit in the circuit,
+// or doesn't have a sibling for each level of its path
+func checkMerkleProof(merkleProof leanIMT.MerkleProof) error {
+	if len(merkleProof.Path) > MAX_DEPTH {
+		return fmt.Errorf("the merkle proof is deeper than %d", MAX_DEPTH)
+	}
+	if len(merkleProof.Path) != len(merkleProof.Siblings) {
+		return fmt.Errorf("the merkle proof has %d levels but %d siblings", len(merkleProof.Path), len(merkleProof.Siblings))
 	}
+	return nil
+}
 
-	// Generate groth16 proof
+// circuitAssignment returns the assignment of the circuit of a semaphore proof from the
+// assignment `s` of the Semaphore circuit: the timestamped circuit if the proof has a timestamp,
+// the circuit of its nullifier mode, or the Semaphore circuit itself
+func circuitAssignment(s *circuits.Semaphore, sProof SemaphoreProof) (frontend.Circuit, error) {
+	if sProof.Timestamp == nil {
+		return nullifierAssignment(s, sProof)
+	}
+	if sProof.NullifierMode != NULLIFIER_PER_SCOPE {
+		return nil, fmt.Errorf("timestamped proofs only have per scope nullifiers")
+	}
+	return timestampAssignment(s, sProof)
+}
+
+// proveWitness generates the groth16 proof of a full witness of the Semaphore circuit
+func proveWitness(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	witness witness.Witness,
+) (*groth16_bn254.Proof, error) {
 	proof, err := groth16.Prove(ccs, pk, witness)
 	if err != nil {
-		return &groth16_bn254.Proof{}, fmt.Errorf("failed to prove witness: %v", err)
+		return &groth16_bn254.Proof{}, fmt.Errorf("failed to prove witness: %w", err)
 	}
-
 	return proof.(*groth16_bn254.Proof), nil
 }
 
-// VerifySemaphoreProof returns nil if the provided proof is correct
+// VerifySemaphoreProof returns nil if the provided proof is correct,
+// else a *VerificationError matching ErrInvalidProof
 func VerifySemaphoreProof(
 	vk groth16.VerifyingKey,
 	proof *groth16_bn254.Proof,
 	sProof SemaphoreProof, // semaphore proof
 ) error {
+	return VerifySemaphoreProofContext(context.Background(), vk, proof, sProof)
+}
+
+// VerifySemaphoreProofContext returns nil if the provided proof is correct,
+// and returns early if `ctx` is done before the proof is verified
+func VerifySemaphoreProofContext(
+	ctx context.Context,
+	vk groth16.VerifyingKey,
+	proof *groth16_bn254.Proof,
+	sProof SemaphoreProof, // semaphore proof
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := checkSignals(sProof); err != nil {
+		return err
+	}
 	dummySquare := new(big.Int).Mul(sProof.Message, sProof.Message)
+	scope, err := circuitScope(sProof)
+	if err != nil {
+		return newVerificationError(ReasonInvalidScope, err)
+	}
 
-	assignment := &circuits.Semaphore{
+	assignment, err := circuitAssignment(&circuits.Semaphore{
 		MerkleRoot:  sProof.MerkleRoot,
 		Message:     sProof.Message,
 		DummySquare: dummySquare,
 		Nullifier:   sProof.Nullifier,
-		Scope:       sProof.Scope,
+		Scope:       scope,
+	}, sProof)
+	if err != nil {
+		return newVerificationError(ReasonInvalidProof, err)
 	}
 	pubWit, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
 	if err != nil {
-		return fmt.Errorf("failed to create public witness: %v", err)
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("failed to create public witness: %w", err))
 	}
 
-	err = groth16.Verify(proof, vk, pubWit)
+	_, err = runContext(ctx, func() (struct{}, error) {
+		return struct{}{}, groth16.Verify(proof, vk, pubWit)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to verify proof: %v", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return newVerificationError(ReasonInvalidProof, err)
+	}
+	return nil
+}
+
+// checkSignals returns a verification error if a public signal of a semaphore proof isn't a
+// canonical field element. The witness reduces the signals modulo r, so that the same proof
+// would verify again with the nullifier plus r, as a new nullifier. The merkle root, the input
+// of the nullifier and the timestamp are only checked if they are set
+func checkSignals(sProof SemaphoreProof) error {
+	signals := []struct {
+		reason   ReasonCode
+		field    string
+		name     string
+		v        *big.Int
+		optional bool
+	}{
+		{ReasonInvalidMessage, "message", "message", sProof.Message, false},
+		{ReasonInvalidScope, "scope", "scope", sProof.Scope, false},
+		{ReasonInvalidMerkleRoot, "merkleRoot", "merkle root", sProof.MerkleRoot, true},
+		{ReasonInvalidProof, "nullifier", "nullifier", sProof.Nullifier, false},
+		{ReasonInvalidNullifierInput, "nullifierInput", "nullifier input", sProof.NullifierInput, true},
+		{ReasonStaleProof, "timestamp", "timestamp", sProof.Timestamp, true},
+	}
+	for _, signal := range signals {
+		if signal.v == nil && signal.optional {
+			continue
+		}
+		if !InField(signal.v) {
+			return newFieldError(signal.reason, signal.field, fmt.Errorf("the %s isn't a field element", signal.name))
+		}
 	}
 	return nil
 }
@@ -0,0 +1,102 @@
+package semaphore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Domain identifies a deployment of a verifier, e.g. an app on a chain. It is hashed into
+// the scope of the proofs, so that a proof accepted by a deployment isn't valid at another
+// one sharing its keys and group roots
+type Domain struct {
+	AppID    string
+	ChainID  uint64
+	Verifier []byte // address of the verifier, e.g. its contract address
+}
+
+// domainJSON is the JSON format of a domain, the address of the verifier is hex encoded
+type domainJSON struct {
+	AppID    string `json:"appId"`
+	ChainID  uint64 `json:"chainId"`
+	Verifier string `json:"verifier,omitempty"`
+}
+
+// check returns an error if the domain doesn't identify a deployment
+func (d Domain) check() error {
+	if d.AppID == "" {
+		return fmt.Errorf("the domain must have an app id")
+	}
+	return nil
+}
+
+// Hash returns the field element of the domain
+func (d Domain) Hash() (*big.Int, error) {
+	return MimcHash([]*big.Int{
+		HashToField([]byte(d.AppID)),
+		new(big.Int).SetUint64(d.ChainID),
+		HashToField(d.Verifier),
+	})
+}
+
+// Scope returns the scope of the circuit, MiMC(domain, scope). The nullifier
+// of a proof bound to the domain is computed with this scope
+func (d Domain) Scope(scope *big.Int) (*big.Int, error) {
+	h, err := d.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return MimcHash([]*big.Int{h, scope})
+}
+
+// MarshalJSON encodes the domain as {"appId", "chainId", "verifier"},
+// the address of the verifier is a 0x prefixed hex string
+func (d Domain) MarshalJSON() ([]byte, error) {
+	dj := domainJSON{AppID: d.AppID, ChainID: d.ChainID}
+	if len(d.Verifier) > 0 {
+		dj.Verifier = "0x" + hex.EncodeToString(d.Verifier)
+	}
+	return json.Marshal(dj)
+}
+
+// UnmarshalJSON decodes a domain encoded by MarshalJSON
+func (d *Domain) UnmarshalJSON(data []byte) error {
+	var dj domainJSON
+	if err := json.Unmarshal(data, &dj); err != nil {
+		return err
+	}
+	domain := Domain{AppID: dj.AppID, ChainID: dj.ChainID}
+	if dj.Verifier != "" {
+		verifier, err := hex.DecodeString(strings.TrimPrefix(dj.Verifier, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid verifier address %q", dj.Verifier)
+		}
+		domain.Verifier = verifier
+	}
+	if err := domain.check(); err != nil {
+		return err
+	}
+	*d = domain
+	return nil
+}
+
+// circuitScope returns the scope of the circuit of a semaphore proof,
+// bound to its domain if any
+func circuitScope(sProof SemaphoreProof) (*big.Int, error) {
+	if sProof.Domain == nil {
+		return sProof.Scope, nil
+	}
+	return sProof.Domain.Scope(sProof.Scope)
+}
+
+// sameDomain returns true if both domains are nil, or hash to the same value
+func sameDomain(a, b *Domain) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ha, errA := a.Hash()
+	hb, errB := b.Hash()
+	return errA == nil && errB == nil && ha.Cmp(hb) == 0
+}
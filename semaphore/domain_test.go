@@ -0,0 +1,93 @@
+package semaphore
+
+import (
+	"encoding/json"
+	"testing"
+
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDomainProof checks that a proof generated for a deployment
+// isn't accepted by another one sharing its keys and group
+func TestDomainProof(t *testing.T) {
+	domainA := &Domain{AppID: "poll", ChainID: 1, Verifier: []byte{0xaa}}
+	domainB := &Domain{AppID: "poll", ChainID: 10, Verifier: []byte{0xaa}}
+	a, b, unbound := newTestSemaphore(t), newTestSemaphore(t), newTestSemaphore(t)
+	require.NoError(t, a.SetDomain(*domainA))
+	require.NoError(t, b.SetDomain(*domainB))
+	require.Error(t, unbound.SetDomain(Domain{ChainID: 1}))
+
+	secrets, idcs := newTestMembers(t, 3)
+	for _, s := range []*Semaphore{a, b, unbound} {
+		require.NoError(t, s.AddMembers(idcs))
+	}
+	require.Equal(t, a.GetGroup().Root(), b.GetGroup().Root())
+
+	merkleProof, err := a.GenerateMerkleProof(1)
+	require.NoError(t, err)
+	scope := randomBigInt()
+	boundScope, err := domainA.Scope(scope)
+	require.NoError(t, err)
+	nullifier, err := ComputeNullifier(NULLIFIER_PER_SCOPE, secrets[1], boundScope, nil)
+	require.NoError(t, err)
+	sProof := SemaphoreProof{
+		MerkleRoot: merkleProof.Root,
+		Nullifier:  nullifier,
+		Message:    randomBigInt(),
+		Scope:      scope,
+		Domain:     domainA,
+	}
+	proof, err := GenerateSemaphoreProof(a.GetCss(), a.GetProvingKey(), secrets[1], merkleProof, sProof)
+	require.NoError(t, err)
+
+	// The proof is only valid for its domain
+	require.ErrorIs(t, b.VerifyProof(proof, sProof), ErrWrongDomain)
+	require.ErrorIs(t, unbound.VerifyProof(proof, sProof), ErrWrongDomain)
+	forged := sProof
+	forged.Domain = domainB
+	require.ErrorIs(t, b.VerifyProof(proof, forged), ErrInvalidProof)
+	forged.Domain = nil
+	require.ErrorIs(t, unbound.VerifyProof(proof, forged), ErrInvalidProof)
+	require.NoError(t, a.VerifyProof(proof, sProof))
+
+	// The nullifiers of a member differ between the domains
+	other, err := domainB.Scope(scope)
+	require.NoError(t, err)
+	otherNullifier, err := ComputeNullifier(NULLIFIER_PER_SCOPE, secrets[1], other, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, nullifier, otherNullifier)
+
+	// The formats carry the domain, or the bound scope
+	jsProof, err := json.Marshal(ToJSProof(proof, sProof, a.GetGroup().Depth()))
+	require.NoError(t, err)
+	var decoded JSSemaphoreProof
+	require.NoError(t, json.Unmarshal(jsProof, &decoded))
+	proof2, sProof2, _, err := FromJSProof(decoded)
+	require.NoError(t, err)
+	require.Equal(t, sProof, sProof2)
+	require.NoError(t, VerifySemaphoreProof(a.GetVerifyingKey(), proof2, sProof2))
+	signals, err := ExportSnarkJSPublicSignals(sProof)
+	require.NoError(t, err)
+	sProof2, err = ImportSnarkJSPublicSignals(signals)
+	require.NoError(t, err)
+	require.Equal(t, boundScope, sProof2.Scope)
+	require.NoError(t, VerifySemaphoreProof(a.GetVerifyingKey(), proof, sProof2))
+
+	invalid, err := BatchVerifySemaphoreProofs(a.GetVerifyingKey(), []*groth16_bn254.Proof{proof, proof}, []SemaphoreProof{sProof, forged})
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, invalid)
+
+	// An instance requiring a domain rejects every proof until it is bound
+	forged.Nullifier, err = ComputeNullifier(NULLIFIER_PER_SCOPE, secrets[1], scope, nil)
+	require.NoError(t, err)
+	unboundProof, err := GenerateSemaphoreProof(a.GetCss(), a.GetProvingKey(), secrets[1], merkleProof, forged)
+	require.NoError(t, err)
+	unbound.RequireDomain()
+	var vErr *VerificationError
+	require.ErrorAs(t, unbound.VerifyProof(unboundProof, forged), &vErr)
+	require.Equal(t, ReasonWrongDomain, vErr.Reason)
+	require.ErrorIs(t, unbound.VerifyProof(proof, sProof), ErrWrongDomain)
+	require.NoError(t, unbound.SetDomain(*domainA))
+	require.NoError(t, unbound.VerifyProof(proof, sProof))
+}
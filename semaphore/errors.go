@@ -0,0 +1,104 @@
+package semaphore
+
+import (
+	"errors"
+)
+
+// Sentinel errors, use errors.Is to check them
+var (
+	ErrInvalidMessage        = errors.New("invalid message")
+	ErrInvalidScope          = errors.New("invalid scope")
+	ErrInvalidMerkleRoot     = errors.New("invalid merkle root")
+	ErrNullifierUsed         = errors.New("the provided nullifier is already used")
+	ErrInvalidProof          = errors.New("invalid proof")
+	ErrStaleProof            = errors.New("the proof is too old or in the future")
+	ErrWrongDomain           = errors.New("the proof is bound to another domain")
+	ErrInvalidNullifierInput = errors.New("the epoch or the app salt of the nullifier isn't accepted")
+	ErrMemberNotFound        = errors.New("the provided identity commitment doesn't exist")
+	ErrProverClosed          = errors.New("the prover is closed")
+)
+
+// ReasonCode identifies why a proof was rejected
+type ReasonCode int
+
+const (
+	ReasonInvalidMessage ReasonCode = iota + 1
+	ReasonInvalidScope
+	ReasonInvalidMerkleRoot
+	ReasonNullifierUsed
+	ReasonInvalidProof
+	ReasonStaleProof
+	ReasonWrongDomain
+	ReasonInvalidNullifierInput
+)
+
+// reasons maps reason codes to their sentinel error and offending field
+var reasons = map[ReasonCode]struct {
+	err   error
+	field string
+	name  string
+}{
+	ReasonInvalidMessage:        {ErrInvalidMessage, "message", "invalid_message"},
+	ReasonInvalidScope:          {ErrInvalidScope, "scope", "invalid_scope"},
+	ReasonInvalidMerkleRoot:     {ErrInvalidMerkleRoot, "merkleRoot", "invalid_merkle_root"},
+	ReasonNullifierUsed:         {ErrNullifierUsed, "nullifier", "nullifier_used"},
+	ReasonInvalidProof:          {ErrInvalidProof, "proof", "invalid_proof"},
+	ReasonStaleProof:            {ErrStaleProof, "timestamp", "stale_proof"},
+	ReasonWrongDomain:           {ErrWrongDomain, "domain", "wrong_domain"},
+	ReasonInvalidNullifierInput: {ErrInvalidNullifierInput, "nullifierInput", "invalid_nullifier_input"},
+}
+
+// String returns the snake case name of the reason code
+func (c ReasonCode) String() string {
+	if r, ok := reasons[c]; ok {
+		return r.name
+	}
+	return "unknown"
+}
+
+// VerificationError is returned when a semaphore proof is rejected.
+// It matches the sentinel error of its reason with errors.Is,
+// and wraps the underlying (gnark) error if any
+type VerificationError struct {
+	Reason ReasonCode
+	Field  string // offending field of the semaphore proof
+	Err    error  // underlying error, may be nil
+}
+
+// newVerificationError returns a verification error for `reason`, wrapping `err`
+func newVerificationError(reason ReasonCode, err error) *VerificationError {
+	return newFieldError(reason, reasons[reason].field, err)
+}
+
+// newFieldError returns a verification error for `reason` whose offending field isn't
+// the field of the reason, e.g. a nullifier out of the field makes the proof invalid
+func newFieldError(reason ReasonCode, field string, err error) *VerificationError {
+	return &VerificationError{
+		Reason: reason,
+		Field:  field,
+		Err:    err,
+	}
+}
+
+func (e *VerificationError) Error() string {
+	msg := e.Reason.String()
+	if r, ok := reasons[e.Reason]; ok {
+		msg = r.err.Error()
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error of the reason and the underlying error
+func (e *VerificationError) Unwrap() []error {
+	errs := []error{}
+	if r, ok := reasons[e.Reason]; ok {
+		errs = append(errs, r.err)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
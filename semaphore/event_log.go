@@ -0,0 +1,184 @@
+package semaphore
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+)
+
+// EventType is the kind of change recorded by a group event
+type EventType int
+
+const (
+	EventMemberAdded EventType = iota + 1
+	EventMembersAdded
+	EventMemberUpdated
+	EventMemberRemoved
+)
+
+var eventTypeNames = map[EventType]string{
+	EventMemberAdded:   "MemberAdded",
+	EventMembersAdded:  "MembersAdded",
+	EventMemberUpdated: "MemberUpdated",
+	EventMemberRemoved: "MemberRemoved",
+}
+
+// String returns the name of the event type
+func (t EventType) String() string {
+	if name, ok := eventTypeNames[t]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// MarshalText encodes the event type by its name
+func (t EventType) MarshalText() ([]byte, error) {
+	if _, ok := eventTypeNames[t]; !ok {
+		return nil, fmt.Errorf("unknown event type %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes an event type from its name
+func (t *EventType) UnmarshalText(text []byte) error {
+	for k, name := range eventTypeNames {
+		if name == string(text) {
+			*t = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown event type %q", text)
+}
+
+// GroupEvent records a change of the group and the resulting root.
+// MemberAdded, MemberUpdated and MemberRemoved use Index, OldCommitment
+// (zero for MemberAdded) and NewCommitment (zero for MemberRemoved),
+// MembersAdded uses Index as the index of the first commitment of NewCommitments
+type GroupEvent struct {
+	Seq            uint64     `json:"seq"`
+	Type           EventType  `json:"type"`
+	Index          int        `json:"index"`
+	OldCommitment  *big.Int   `json:"oldCommitment,omitempty"`
+	NewCommitment  *big.Int   `json:"newCommitment,omitempty"`
+	NewCommitments []*big.Int `json:"newCommitments,omitempty"`
+	Root           *big.Int   `json:"root"`
+}
+
+// EventLog is an append-only log of group events, safe for concurrent use
+type EventLog struct {
+	mu     sync.RWMutex
+	events []GroupEvent
+}
+
+// NewEventLog returns an empty event log
+func NewEventLog() *EventLog {
+	return &EventLog{}
+}
+
+// Append records an event and returns it with its sequence number
+func (l *EventLog) Append(e GroupEvent) GroupEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Seq = uint64(len(l.events))
+	l.events = append(l.events, e)
+	return e
+}
+
+// Len returns the number of recorded events
+func (l *EventLog) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.events)
+}
+
+// Events returns a copy of all the recorded events
+func (l *EventLog) Events() []GroupEvent {
+	return l.Since(0)
+}
+
+// Since returns a copy of the recorded events from sequence number `seq`
+func (l *EventLog) Since(seq uint64) []GroupEvent {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if seq >= uint64(len(l.events)) {
+		return []GroupEvent{}
+	}
+	return append([]GroupEvent{}, l.events[seq:]...)
+}
+
+// ReplayEvents rebuilds the group tree from its event log,
+// checking the root after each event against the recorded one
+func ReplayEvents(events []GroupEvent) (*leanIMT.LeanIMT, error) {
+	imt, err := leanIMT.NewLeanIMT(MimcHash, []*big.Int{})
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range events {
+		if e.Seq != uint64(i) {
+			return nil, fmt.Errorf("event %d has sequence number %d", i, e.Seq)
+		}
+		if err := ApplyEvent(imt, e); err != nil {
+			return nil, err
+		}
+	}
+	return imt, nil
+}
+
+// ApplyEvent applies a group event to a tree and checks that the
+// resulting root matches the recorded one. The tree is left as is
+// if the event doesn't apply to it, but not if the roots differ.
+// The commitments are checked before the tree is changed, so that
+// hashing them can't fail halfway through an insertion or an update
+func ApplyEvent(imt *leanIMT.LeanIMT, e GroupEvent) error {
+	if e.Root == nil {
+		return fmt.Errorf("event %d: missing root", e.Seq)
+	}
+	var err error
+	switch e.Type {
+	case EventMemberAdded:
+		if e.Index != imt.Size() || e.NewCommitment == nil {
+			return fmt.Errorf("event %d: invalid member added at index %d", e.Seq, e.Index)
+		}
+		if !InField(e.NewCommitment) {
+			return fmt.Errorf("event %d: the commitment %v isn't a field element", e.Seq, e.NewCommitment)
+		}
+		err = imt.Insert(e.NewCommitment)
+	case EventMembersAdded:
+		if e.Index != imt.Size() || len(e.NewCommitments) == 0 {
+			return fmt.Errorf("event %d: invalid members added at index %d", e.Seq, e.Index)
+		}
+		for _, idc := range e.NewCommitments {
+			if !InField(idc) {
+				return fmt.Errorf("event %d: the commitment %v isn't a field element", e.Seq, idc)
+			}
+		}
+		err = imt.InsertMany(e.NewCommitments)
+	case EventMemberUpdated, EventMemberRemoved:
+		if e.Index < 0 || e.Index >= imt.Size() || e.OldCommitment == nil {
+			return fmt.Errorf("event %d: invalid index %d", e.Seq, e.Index)
+		}
+		if imt.Nodes[0][e.Index].Cmp(e.OldCommitment) != 0 {
+			return fmt.Errorf("event %d: the old commitment doesn't match the leaf at index %d", e.Seq, e.Index)
+		}
+		newIdc := e.NewCommitment
+		if e.Type == EventMemberRemoved {
+			newIdc = big.NewInt(0)
+		} else if newIdc == nil {
+			return fmt.Errorf("event %d: missing new commitment", e.Seq)
+		} else if !InField(newIdc) {
+			return fmt.Errorf("event %d: the commitment %v isn't a field element", e.Seq, newIdc)
+		}
+		err = imt.Update(newIdc, e.Index)
+	default:
+		return fmt.Errorf("event %d: unknown event type %d", e.Seq, int(e.Type))
+	}
+	if err != nil {
+		return fmt.Errorf("event %d: %w", e.Seq, err)
+	}
+	if imt.Root().Cmp(e.Root) != 0 {
+		return fmt.Errorf("event %d: the replayed root %v doesn't match the recorded root %v", e.Seq, imt.Root(), e.Root)
+	}
+	return nil
+}
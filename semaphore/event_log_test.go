@@ -0,0 +1,75 @@
+package semaphore
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventLog checks that the changes of a group are recorded and that
+// replaying them rebuilds the same tree
+func TestEventLog(t *testing.T) {
+	s := NewSemaphoreWithKeys(nil, nil, nil)
+	idcs := randomBigIntArray(6)
+	require.NoError(t, s.AddMember(idcs[0]))
+	require.NoError(t, s.AddMembers(idcs[1:5]))
+	require.NoError(t, s.UpdateMember(idcs[2], idcs[5]))
+	require.NoError(t, s.RemoveMember(idcs[3], nil))
+	require.ErrorIs(t, s.RemoveMember(idcs[3], nil), ErrMemberNotFound)
+
+	events := s.GetEventLog().Events()
+	require.Len(t, events, 4)
+	require.Equal(t, EventMemberAdded, events[0].Type)
+	require.Equal(t, EventMembersAdded, events[1].Type)
+	require.Equal(t, 1, events[1].Index)
+	require.Equal(t, EventMemberUpdated, events[2].Type)
+	require.Equal(t, 2, events[2].Index)
+	require.Equal(t, idcs[5], events[2].NewCommitment)
+	require.Equal(t, EventMemberRemoved, events[3].Type)
+	require.Equal(t, uint64(3), events[3].Seq)
+	require.Equal(t, s.GetGroup().Root(), events[3].Root)
+	require.Len(t, s.GetEventLog().Since(2), 2)
+	require.Empty(t, s.GetEventLog().Since(4))
+
+	// Replay through JSON
+	data, err := json.Marshal(events)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"type":"MembersAdded"`)
+	var decoded []GroupEvent
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	imt, err := ReplayEvents(decoded)
+	require.NoError(t, err)
+	require.Equal(t, s.GetGroup().Root(), imt.Root())
+	require.Equal(t, s.GetGroup().Nodes[0], imt.Nodes[0])
+
+	// Tampered logs are rejected
+	tampered := append([]GroupEvent{}, events...)
+	tampered[1].Root = big.NewInt(1)
+	_, err = ReplayEvents(tampered)
+	require.ErrorContains(t, err, "event 1")
+
+	tampered = append([]GroupEvent{}, events...)
+	tampered[2].OldCommitment = idcs[0]
+	_, err = ReplayEvents(tampered)
+	require.ErrorContains(t, err, "event 2")
+
+	_, err = ReplayEvents(events[1:])
+	require.Error(t, err)
+
+	// Commitments out of the field are rejected before the tree is changed
+	size, root := imt.Size(), new(big.Int).Set(imt.Root())
+	outOfField := new(big.Int).Add(fr.Modulus(), big.NewInt(1))
+	invalid := []GroupEvent{
+		{Seq: 4, Type: EventMemberAdded, Index: imt.Size(), NewCommitment: outOfField, Root: root},
+		{Seq: 4, Type: EventMembersAdded, Index: imt.Size(), NewCommitments: []*big.Int{idcs[0], outOfField}, Root: root},
+		{Seq: 4, Type: EventMemberUpdated, Index: 0, OldCommitment: idcs[0], NewCommitment: outOfField, Root: root},
+	}
+	for _, e := range invalid {
+		require.ErrorContains(t, ApplyEvent(imt, e), "isn't a field element")
+		require.Equal(t, size, imt.Size())
+		require.Equal(t, root, imt.Root())
+	}
+}
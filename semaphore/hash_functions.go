@@ -1,19 +1,28 @@
 package semaphore
 
 import (
+	"fmt"
 	"math/big"
 
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"golang.org/x/crypto/sha3"
 )
 
 // MimcHash extends the `func([]*big.Int) ([]*big.Int, error)` function interface
 // to be used in the semaphore hash function
-// (currently, gnark doesn't support poseidon hash function)
+// (currently, gnark doesn't support poseidon hash function).
+// The inputs must be field elements, in [0, r)
 func MimcHash(inpBI []*big.Int) (*big.Int, error) {
 	hasher := mimc.NewMiMC()
 	hasher.Reset()
 	for i := 0; i < len(inpBI); i++ {
-		if _, err := hasher.Write(inpBI[i].Bytes()); err != nil {
+		if !InField(inpBI[i]) {
+			return nil, fmt.Errorf("the input %d isn't a field element", i)
+		}
+		// Write full field elements, so that zero values (e.g. removed members) are hashed too
+		b := inpBI[i].FillBytes(make([]byte, fr.Bytes))
+		if _, err := hasher.Write(b); err != nil {
 			return nil, err
 		}
 	}
@@ -21,3 +30,18 @@ func MimcHash(inpBI []*big.Int) (*big.Int, error) {
 	res.SetBytes(hasher.Sum(nil))
 	return res, nil
 }
+
+// InField returns true if `v` is a canonical field element, in [0, r).
+// Values out of the field are reduced by the circuit, so they alias other values
+func InField(v *big.Int) bool {
+	return v != nil && v.Sign() >= 0 && v.Cmp(fr.Modulus()) < 0
+}
+
+// HashToField hashes arbitrary data into a field element as the `hash` function of
+// the JavaScript implementation does: keccak256(data) >> 8, e.g. to use a poll ID as a scope
+func HashToField(data []byte) *big.Int {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	res := new(big.Int).SetBytes(h.Sum(nil))
+	return res.Rsh(res, 8)
+}
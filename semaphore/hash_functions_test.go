@@ -0,0 +1,79 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// mimcCircuit checks that the MiMC hash of two inputs, computed in circuit, is Hash
+type mimcCircuit struct {
+	Inputs [2]frontend.Variable
+	Hash   frontend.Variable `gnark:",public"`
+}
+
+func (c *mimcCircuit) Define(api frontend.API) error {
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	hFunc.Write(c.Inputs[:]...)
+	api.AssertIsEqual(hFunc.Sum(), c.Hash)
+	return nil
+}
+
+// TestMimcHashCircuit checks that MimcHash matches the MiMC of the circuits,
+// including zero inputs such as the leaves of removed members
+func TestMimcHashCircuit(t *testing.T) {
+	inputs := [][2]*big.Int{
+		{big.NewInt(0), big.NewInt(0)},
+		{big.NewInt(0), big.NewInt(5)},
+		{big.NewInt(7), big.NewInt(0)},
+		{randomBigInt(), randomBigInt()},
+	}
+	for _, in := range inputs {
+		h, err := MimcHash(in[:])
+		require.NoError(t, err)
+		assignment := &mimcCircuit{Inputs: [2]frontend.Variable{in[0], in[1]}, Hash: h}
+		require.NoError(t, test.IsSolved(&mimcCircuit{}, assignment, ecc.BN254.ScalarField()))
+	}
+
+	// The zero inputs are hashed
+	h0, err := MimcHash([]*big.Int{big.NewInt(0), big.NewInt(5)})
+	require.NoError(t, err)
+	h1, err := MimcHash([]*big.Int{big.NewInt(5)})
+	require.NoError(t, err)
+	require.NotEqual(t, h0, h1)
+}
+
+// TestHashToField checks HashToField against keccak256 vectors
+func TestHashToField(t *testing.T) {
+	// keccak256("") >> 8
+	require.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a4", HashToField(nil).Text(16))
+	// keccak256("abc") >> 8
+	require.Equal(t, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c", HashToField([]byte("abc")).Text(16))
+
+	for _, data := range []string{"", "poll-1", "Town President Election"} {
+		require.Negative(t, HashToField([]byte(data)).Cmp(fr.Modulus()))
+	}
+}
+
+// TestMimcHashField checks that MimcHash only hashes field elements
+func TestMimcHashField(t *testing.T) {
+	r := fr.Modulus()
+	last := new(big.Int).Sub(r, big.NewInt(1))
+	h, err := MimcHash([]*big.Int{big.NewInt(0), last})
+	require.NoError(t, err)
+	require.True(t, InField(h))
+
+	for _, v := range []*big.Int{nil, big.NewInt(-1), r, new(big.Int).Add(r, big.NewInt(1))} {
+		_, err := MimcHash([]*big.Int{big.NewInt(1), v})
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,135 @@
+package semaphore
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// JSSemaphoreProof is the JSON format of the `SemaphoreProof` type of the
+// JavaScript `@semaphore-protocol/proof` package.
+//
+// `Points` is the packed groth16 proof, in the order expected by the Solidity verifier:
+//
+//	[A.x, A.y, B.x.a1, B.x.a0, B.y.a1, B.y.a0, C.x, C.y]
+//
+// Note that the JavaScript package hashes the message and the scope before using them
+// as public inputs, and uses the Poseidon hash function, so its proofs are only accepted
+// once the hash functions and the keys of both sides match. `Domain` is only set for the
+// proofs bound to a domain, their scope is hashed with the domain in the circuit
+type JSSemaphoreProof struct {
+	MerkleTreeDepth int       `json:"merkleTreeDepth"`
+	MerkleTreeRoot  string    `json:"merkleTreeRoot"`
+	Nullifier       string    `json:"nullifier"`
+	Message         string    `json:"message"`
+	Scope           string    `json:"scope"`
+	Points          [8]string `json:"points"`
+	Domain          *Domain   `json:"domain,omitempty"`
+}
+
+// ToJSProof converts a semaphore proof and its groth16 proof into the JavaScript format,
+// `depth` is the depth of the group tree the proof was generated for
+func ToJSProof(proof *groth16_bn254.Proof, sProof SemaphoreProof, depth int) JSSemaphoreProof {
+	return JSSemaphoreProof{
+		MerkleTreeDepth: depth,
+		MerkleTreeRoot:  sProof.MerkleRoot.String(),
+		Nullifier:       sProof.Nullifier.String(),
+		Message:         sProof.Message.String(),
+		Scope:           sProof.Scope.String(),
+		Points:          PackPoints(proof),
+		Domain:          sProof.Domain,
+	}
+}
+
+// FromJSProof converts a proof in the JavaScript format into a semaphore proof,
+// its groth16 proof and the depth of the group tree. The public signals must be
+// field elements, so that they can't alias other values modulo r
+func FromJSProof(jsProof JSSemaphoreProof) (*groth16_bn254.Proof, SemaphoreProof, int, error) {
+	var sProof SemaphoreProof
+	if jsProof.MerkleTreeDepth < MIN_DEPTH || jsProof.MerkleTreeDepth > MAX_DEPTH {
+		return nil, sProof, 0, fmt.Errorf("the tree depth must be between %d and %d", MIN_DEPTH, MAX_DEPTH)
+	}
+
+	signals := []*big.Int{}
+	for _, s := range []string{jsProof.MerkleTreeRoot, jsProof.Nullifier, jsProof.Message, jsProof.Scope} {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok || !InField(v) {
+			return nil, sProof, 0, fmt.Errorf("invalid public signal %q", s)
+		}
+		signals = append(signals, v)
+	}
+	sProof.MerkleRoot, sProof.Nullifier, sProof.Message, sProof.Scope = signals[0], signals[1], signals[2], signals[3]
+	if jsProof.Domain != nil {
+		if err := jsProof.Domain.check(); err != nil {
+			return nil, sProof, 0, err
+		}
+		sProof.Domain = jsProof.Domain
+	}
+
+	proof, err := UnpackPoints(jsProof.Points)
+	if err != nil {
+		return nil, sProof, 0, err
+	}
+	return proof, sProof, jsProof.MerkleTreeDepth, nil
+}
+
+// PackPoints packs a groth16 proof into 8 decimal strings, as `packGroth16Proof` does
+func PackPoints(proof *groth16_bn254.Proof) [8]string {
+	return [8]string{
+		proof.Ar.X.String(),
+		proof.Ar.Y.String(),
+		proof.Bs.X.A1.String(),
+		proof.Bs.X.A0.String(),
+		proof.Bs.Y.A1.String(),
+		proof.Bs.Y.A0.String(),
+		proof.Krs.X.String(),
+		proof.Krs.Y.String(),
+	}
+}
+
+// UnpackPoints unpacks 8 decimal strings into a groth16 proof, as `unpackGroth16Proof` does,
+// and checks that the points are on the curve and in the right subgroup
+func UnpackPoints(points [8]string) (*groth16_bn254.Proof, error) {
+	coords := [8]fp.Element{}
+	for i, s := range points {
+		var err error
+		if coords[i], err = parseCoordinate(s); err != nil {
+			return nil, err
+		}
+	}
+
+	proof := new(groth16_bn254.Proof)
+	proof.Ar = bn254.G1Affine{X: coords[0], Y: coords[1]}
+	proof.Bs.X.A1, proof.Bs.X.A0 = coords[2], coords[3]
+	proof.Bs.Y.A1, proof.Bs.Y.A0 = coords[4], coords[5]
+	proof.Krs = bn254.G1Affine{X: coords[6], Y: coords[7]}
+
+	if err := checkProofPoints(proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// parseCoordinate parses a decimal base field element
+func parseCoordinate(s string) (fp.Element, error) {
+	var e fp.Element
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 || v.Cmp(fp.Modulus()) >= 0 {
+		return e, fmt.Errorf("invalid point coordinate %q", s)
+	}
+	e.SetBigInt(v)
+	return e, nil
+}
+
+// checkProofPoints checks that the points of a groth16 proof are on the curve
+// and in the right subgroup
+func checkProofPoints(proof *groth16_bn254.Proof) error {
+	// G1 has a cofactor of 1, so only B requires a subgroup check
+	if !proof.Ar.IsOnCurve() || !proof.Krs.IsOnCurve() || !proof.Bs.IsOnCurve() || !proof.Bs.IsInSubGroup() {
+		return fmt.Errorf("invalid proof points")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package semaphore
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSProof checks that proofs converted to the JavaScript format and back are still valid
+func TestJSProof(t *testing.T) {
+	s, proof, sProof := newTestProof(t, 5)
+	depth := s.GetGroup().Depth()
+
+	// Check the packing order
+	jsProof := ToJSProof(proof, sProof, depth)
+	require.Equal(t, proof.Ar.X.String(), jsProof.Points[0])
+	require.Equal(t, proof.Bs.X.A1.String(), jsProof.Points[2])
+	require.Equal(t, proof.Bs.X.A0.String(), jsProof.Points[3])
+	require.Equal(t, proof.Krs.Y.String(), jsProof.Points[7])
+	require.Equal(t, sProof.Nullifier.String(), jsProof.Nullifier)
+
+	// Round trip through JSON
+	data, err := json.Marshal(jsProof)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"merkleTreeRoot"`)
+	var decoded JSSemaphoreProof
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, jsProof, decoded)
+
+	proof2, sProof2, depth2, err := FromJSProof(decoded)
+	require.NoError(t, err)
+	require.Equal(t, depth, depth2)
+	require.Equal(t, sProof, sProof2)
+	require.Equal(t, proof.Ar, proof2.Ar)
+	require.Equal(t, proof.Bs, proof2.Bs)
+	require.Equal(t, proof.Krs, proof2.Krs)
+	require.NoError(t, VerifySemaphoreProof(s.GetVerifyingKey(), proof2, sProof2))
+
+	// Invalid points are rejected
+	badProof := decoded
+	badProof.Points[2], badProof.Points[3] = badProof.Points[3], badProof.Points[2]
+	_, _, _, err = FromJSProof(badProof)
+	require.Error(t, err)
+	badProof = decoded
+	badProof.Points[0] = "abc"
+	_, _, _, err = FromJSProof(badProof)
+	require.Error(t, err)
+
+	// Signals out of the field are rejected, they would alias other values modulo r
+	r := fr.Modulus()
+	for _, v := range []*big.Int{new(big.Int).Add(sProof.Nullifier, r), new(big.Int).Sub(sProof.Nullifier, r)} {
+		badProof = decoded
+		badProof.Nullifier = v.String()
+		_, _, _, err = FromJSProof(badProof)
+		require.Error(t, err)
+	}
+	badProof = decoded
+	badProof.Scope = new(big.Int).Add(sProof.Scope, r).String()
+	_, _, _, err = FromJSProof(badProof)
+	require.Error(t, err)
+
+	// Invalid depth is rejected
+	badProof = decoded
+	badProof.MerkleTreeDepth = MAX_DEPTH + 1
+	_, _, _, err = FromJSProof(badProof)
+	require.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// SetupKeyClaimCircuit performs the setup phase of the circuit proving that a Baby Jubjub
+// public key and an identity commitment share their secret
+func SetupKeyClaimCircuit() (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	return setupCircuitContext(context.Background(), &circuits.KeyClaim{})
+}
+
+// GenerateKeyClaimProof returns the groth16 proof that the public key (x, y) is
+// secret·B8, and that the identity commitment is MiMC(secret)
+func GenerateKeyClaimProof(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	secret, x, y *big.Int,
+) (*groth16_bn254.Proof, error) {
+	commitment, err := MimcHash([]*big.Int{secret})
+	if err != nil {
+		return nil, err
+	}
+	if !InField(x) || !InField(y) {
+		return nil, fmt.Errorf("the public key must be made of field elements")
+	}
+	assignment := &circuits.KeyClaim{Secret: secret, Commitment: commitment, PublicKeyX: x, PublicKeyY: y}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate witness: %v", err)
+	}
+	return proveWitness(ccs, pk, witness)
+}
+
+// VerifyKeyClaimProof returns nil if the proof shows that the public key (x, y)
+// and the identity commitment share their secret
+func VerifyKeyClaimProof(
+	vk groth16.VerifyingKey,
+	proof *groth16_bn254.Proof,
+	commitment, x, y *big.Int,
+) error {
+	if !InField(commitment) || !InField(x) || !InField(y) {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("the commitment and the public key must be field elements"))
+	}
+	assignment := &circuits.KeyClaim{Commitment: commitment, PublicKeyX: x, PublicKeyY: y}
+	pubWit, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("failed to create public witness: %w", err))
+	}
+	if err := groth16.Verify(proof, vk, pubWit); err != nil {
+		return newVerificationError(ReasonInvalidProof, err)
+	}
+	return nil
+}
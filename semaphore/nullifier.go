@@ -0,0 +1,134 @@
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// NullifierMode is the derivation of the nullifier, see circuits.NullifierMode
+type NullifierMode = circuits.NullifierMode
+
+const (
+	NULLIFIER_PER_SCOPE = circuits.NULLIFIER_PER_SCOPE
+	NULLIFIER_PER_EPOCH = circuits.NULLIFIER_PER_EPOCH
+	NULLIFIER_PER_APP   = circuits.NULLIFIER_PER_APP
+)
+
+// NB_NULLIFIER_PUBLIC is the number of public inputs of the SemaphoreNullifier circuit
+const NB_NULLIFIER_PUBLIC = 6
+
+// NullifierConfig is the derivation of the nullifiers accepted by a Semaphore instance,
+// and the epochs or the app salt their inputs must match
+type NullifierConfig struct {
+	Mode        NullifierMode
+	EpochPeriod time.Duration // per epoch, the length of an epoch
+	EpochWindow int64         // per epoch, the number of epochs accepted before and after the current one
+	AppSalt     *big.Int      // per app, the salt of the app
+}
+
+// check returns an error if the configuration is incomplete
+func (cfg NullifierConfig) check() error {
+	switch cfg.Mode {
+	case NULLIFIER_PER_SCOPE:
+		return nil
+	case NULLIFIER_PER_EPOCH:
+		if cfg.EpochPeriod <= 0 || cfg.EpochWindow < 0 {
+			return fmt.Errorf("the epoch period must be positive and the window can't be negative")
+		}
+		return nil
+	case NULLIFIER_PER_APP:
+		if !InField(cfg.AppSalt) {
+			return fmt.Errorf("the app salt must be a field element")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown nullifier mode %d", cfg.Mode)
+}
+
+// SetupNullifierCircuit performs the setup phase of the Semaphore circuit deriving the nullifier with `mode`
+func SetupNullifierCircuit(mode NullifierMode) (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	return SetupNullifierCircuitContext(context.Background(), mode)
+}
+
+// SetupNullifierCircuitContext performs the setup phase like SetupNullifierCircuit,
+// and returns early if `ctx` is done before the compilation or the setup completes
+func SetupNullifierCircuitContext(ctx context.Context, mode NullifierMode) (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	switch mode {
+	case NULLIFIER_PER_SCOPE:
+		return setupCircuitContext(ctx, &circuits.Semaphore{})
+	case NULLIFIER_PER_EPOCH, NULLIFIER_PER_APP:
+		return setupCircuitContext(ctx, &circuits.SemaphoreNullifier{Mode: mode})
+	}
+	return nil, nil, nil, fmt.Errorf("unknown nullifier mode %d", mode)
+}
+
+// ComputeNullifier returns the nullifier of `secret` in `scope`, `input` is the epoch
+// or the app salt depending on `mode`, and is ignored per scope
+func ComputeNullifier(mode NullifierMode, secret, scope, input *big.Int) (*big.Int, error) {
+	if mode != NULLIFIER_PER_SCOPE && input == nil {
+		return nil, fmt.Errorf("the %v nullifier needs an input", mode)
+	}
+	switch mode {
+	case NULLIFIER_PER_SCOPE:
+		return MimcHash([]*big.Int{scope, secret})
+	case NULLIFIER_PER_EPOCH:
+		return MimcHash([]*big.Int{scope, input, secret})
+	case NULLIFIER_PER_APP:
+		return MimcHash([]*big.Int{input, scope, secret})
+	}
+	return nil, fmt.Errorf("unknown nullifier mode %d", mode)
+}
+
+// Epoch returns the number of whole periods of `period` since the unix epoch at `t`,
+// e.g. the day of `t` if `period` is 24h
+func Epoch(t time.Time, period time.Duration) (*big.Int, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("the epoch period must be positive")
+	}
+	return big.NewInt(t.UnixNano() / int64(period)), nil
+}
+
+// nullifierAssignment returns the assignment of the circuit of the nullifier of a semaphore
+// proof from the assignment `s` of the Semaphore circuit, `s` itself per scope
+func nullifierAssignment(s *circuits.Semaphore, sProof SemaphoreProof) (frontend.Circuit, error) {
+	switch sProof.NullifierMode {
+	case NULLIFIER_PER_SCOPE:
+		return s, nil
+	case NULLIFIER_PER_EPOCH, NULLIFIER_PER_APP:
+	default:
+		return nil, fmt.Errorf("unknown nullifier mode %d", sProof.NullifierMode)
+	}
+	if sProof.NullifierInput == nil {
+		return nil, fmt.Errorf("the %v nullifier needs an input", sProof.NullifierMode)
+	}
+	return &circuits.SemaphoreNullifier{
+		Mode:                sProof.NullifierMode,
+		Secret:              s.Secret,
+		MerkleProofLength:   s.MerkleProofLength,
+		MerkleProofIndices:  s.MerkleProofIndices,
+		MerkleProofSiblings: s.MerkleProofSiblings,
+		Message:             s.Message,
+		Scope:               s.Scope,
+		DummySquare:         s.DummySquare,
+		MerkleRoot:          s.MerkleRoot,
+		Nullifier:           s.Nullifier,
+		NullifierInput:      sProof.NullifierInput,
+	}, nil
+}
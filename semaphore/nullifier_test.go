@@ -0,0 +1,118 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEpoch checks the epochs of short and long periods
+func TestEpoch(t *testing.T) {
+	at := time.Unix(1700000000, 500_000_000)
+	epoch, err := Epoch(at, 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1700000000/86400), epoch)
+	epoch, err = Epoch(at, 100*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(17000000005), epoch)
+	_, err = Epoch(at, 0)
+	require.Error(t, err)
+	_, err = Epoch(at, -time.Second)
+	require.Error(t, err)
+}
+
+// TestNullifierModes checks that a member signals once per scope and epoch, and once per scope
+// and app salt, and that the verifier only accepts the current epochs and its own app salt
+func TestNullifierModes(t *testing.T) {
+	secret := randomBigInt()
+	scope := randomBigInt()
+
+	nullifier, err := ComputeNullifier(NULLIFIER_PER_SCOPE, secret, scope, nil)
+	require.NoError(t, err)
+	expected, err := MimcHash([]*big.Int{scope, secret})
+	require.NoError(t, err)
+	require.Equal(t, expected, nullifier)
+	_, err = ComputeNullifier(NULLIFIER_PER_EPOCH, secret, scope, nil)
+	require.Error(t, err)
+
+	day := 24 * time.Hour
+	epoch := func(at time.Time) *big.Int {
+		e, err := Epoch(at, day)
+		require.NoError(t, err)
+		return e
+	}
+	now := time.Now()
+	salt := HashToField([]byte("app-1"))
+	tests := []struct {
+		cfg      NullifierConfig
+		accepted []*big.Int
+		rejected []*big.Int
+	}{
+		{
+			NullifierConfig{Mode: NULLIFIER_PER_EPOCH, EpochPeriod: day, EpochWindow: 1},
+			[]*big.Int{epoch(now), epoch(now.Add(-day))},
+			[]*big.Int{epoch(now.Add(2 * day)), epoch(now.Add(-3 * day))},
+		},
+		{
+			NullifierConfig{Mode: NULLIFIER_PER_APP, AppSalt: salt},
+			[]*big.Int{salt},
+			[]*big.Int{HashToField([]byte("app-2"))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cfg.Mode.String(), func(t *testing.T) {
+			ccs, pk, vk, err := SetupNullifierCircuit(tt.cfg.Mode)
+			require.NoError(t, err)
+			s := NewSemaphoreWithKeys(ccs, pk, vk)
+			require.Error(t, s.SetNullifierConfig(NullifierConfig{Mode: tt.cfg.Mode}))
+			require.Error(t, newTestSemaphore(t).SetNullifierConfig(tt.cfg))
+			require.NoError(t, s.SetNullifierConfig(tt.cfg))
+			idc, err := MimcHash([]*big.Int{secret})
+			require.NoError(t, err)
+			require.NoError(t, s.AddMembers([]*big.Int{randomBigInt(), idc}))
+			merkleProof, err := s.GenerateMerkleProof(1)
+			require.NoError(t, err)
+
+			prove := func(input *big.Int) (*SemaphoreProof, error) {
+				nullifier, err := ComputeNullifier(tt.cfg.Mode, secret, scope, input)
+				require.NoError(t, err)
+				sProof := SemaphoreProof{
+					MerkleRoot:     s.GetGroup().Root(),
+					Nullifier:      nullifier,
+					Message:        randomBigInt(),
+					Scope:          scope,
+					NullifierMode:  tt.cfg.Mode,
+					NullifierInput: input,
+				}
+				proof, err := GenerateSemaphoreProof(ccs, pk, secret, merkleProof, sProof)
+				require.NoError(t, err)
+				require.NoError(t, VerifySemaphoreProof(vk, proof, sProof))
+
+				// The nullifier is bound to its input and its mode
+				forged := sProof
+				forged.NullifierInput = new(big.Int).Add(input, big.NewInt(1))
+				require.ErrorIs(t, VerifySemaphoreProof(vk, proof, forged), ErrInvalidProof)
+				forged = sProof
+				forged.NullifierMode = NULLIFIER_PER_SCOPE
+				require.ErrorIs(t, s.VerifyProof(proof, forged), ErrInvalidNullifierInput)
+				return &sProof, s.VerifyProof(proof, sProof)
+			}
+
+			nullifiers := map[string]bool{}
+			for _, input := range tt.accepted {
+				sProof, err := prove(input)
+				require.NoError(t, err)
+				nullifiers[sProof.Nullifier.String()] = true
+				require.True(t, s.IsNullifierUsed(sProof.Nullifier))
+			}
+			require.Len(t, nullifiers, len(tt.accepted))
+			for _, input := range tt.rejected {
+				sProof, err := prove(input)
+				require.ErrorIs(t, err, ErrInvalidNullifierInput)
+				require.False(t, s.IsNullifierUsed(sProof.Nullifier))
+			}
+		})
+	}
+}
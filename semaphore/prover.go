@@ -0,0 +1,263 @@
+package semaphore
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+)
+
+// ProverConfig configures a Prover
+type ProverConfig struct {
+	Workers   int           // number of proofs generated in parallel, defaults to the number of CPUs
+	QueueSize int           // number of jobs waiting for a worker before Submit blocks, defaults to 64
+	CacheSize int           // number of cached witnesses, 0 disables the cache
+	Timeout   time.Duration // default timeout of a job, 0 means no timeout
+}
+
+// ProofJob holds the inputs of a semaphore proof
+type ProofJob struct {
+	Secret      *big.Int
+	MerkleProof leanIMT.MerkleProof
+	SProof      SemaphoreProof
+}
+
+// ProofMetrics holds the timings of a job
+type ProofMetrics struct {
+	QueueWait     time.Duration // time spent waiting for a worker
+	WitnessTime   time.Duration // time spent building the witness, zero if it was cached
+	ProveTime     time.Duration // time spent generating the groth16 proof
+	Total         time.Duration // time between the submission and the result
+	CachedWitness bool          // true if the witness was found in the cache
+}
+
+// ProofResult is the result of a job
+type ProofResult struct {
+	Proof   *groth16_bn254.Proof
+	Metrics ProofMetrics
+	Err     error
+}
+
+// proverTask is a job queued in a Prover
+type proverTask struct {
+	ctx       context.Context
+	job       ProofJob
+	submitted time.Time
+	result    chan ProofResult
+}
+
+// Prover generates semaphore proofs on a pool of workers sharing
+// the constraint system and the proving key of the Semaphore circuit
+type Prover struct {
+	ccs     constraint.ConstraintSystem
+	pk      groth16.ProvingKey
+	timeout time.Duration
+
+	tasks  chan *proverTask
+	wg     sync.WaitGroup
+	mu     sync.RWMutex // guards closed, so that no task is sent to a closed queue
+	closed bool
+
+	cache *witnessCache
+}
+
+// NewProver starts a prover pool with the provided configuration
+func NewProver(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, cfg ProverConfig) *Prover {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 64
+	}
+	p := &Prover{
+		ccs:     ccs,
+		pk:      pk,
+		timeout: cfg.Timeout,
+		tasks:   make(chan *proverTask, cfg.QueueSize),
+		cache:   newWitnessCache(cfg.CacheSize),
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues a job and returns the channel receiving its result. Jobs with a malformed
+// merkle proof fail right away, the job is abandoned if `ctx` is done before a worker picks it up
+func (p *Prover) Submit(ctx context.Context, job ProofJob) <-chan ProofResult {
+	result := make(chan ProofResult, 1)
+	task := &proverTask{ctx: ctx, job: job, submitted: time.Now(), result: result}
+	if err := checkMerkleProof(job.MerkleProof); err != nil {
+		result <- ProofResult{Err: err}
+		return result
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		result <- ProofResult{Err: ErrProverClosed}
+		return result
+	}
+	select {
+	case p.tasks <- task:
+	case <-ctx.Done():
+		result <- ProofResult{Err: ctx.Err(), Metrics: ProofMetrics{Total: time.Since(task.submitted)}}
+	}
+	return result
+}
+
+// Prove queues a job and waits for its result, or until `ctx` is done or the
+// default timeout expires. The groth16 prover can't be interrupted, so a job
+// already being proved when `ctx` is done completes in the background and its
+// witness stays cached for a retry
+func (p *Prover) Prove(ctx context.Context, job ProofJob) (*groth16_bn254.Proof, ProofMetrics, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	select {
+	case res := <-p.Submit(ctx, job):
+		return res.Proof, res.Metrics, res.Err
+	case <-ctx.Done():
+		return nil, ProofMetrics{Total: time.Since(start)}, ctx.Err()
+	}
+}
+
+// Close stops accepting jobs and waits for the queued jobs to complete
+func (p *Prover) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// work runs the queued jobs until the prover is closed
+func (p *Prover) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		task.result <- p.run(task)
+	}
+}
+
+// run generates the proof of a task
+func (p *Prover) run(task *proverTask) (res ProofResult) {
+	start := time.Now()
+	res.Metrics.QueueWait = start.Sub(task.submitted)
+	defer func() { res.Metrics.Total = time.Since(task.submitted) }()
+
+	// Skip abandoned jobs
+	if err := task.ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+
+	// Build or reuse the witness
+	key := witnessKey(task.job)
+	w, ok := p.cache.get(key)
+	res.Metrics.CachedWitness = ok
+	if !ok {
+		var err error
+		w, err = NewSemaphoreWitness(task.job.Secret, task.job.MerkleProof, task.job.SProof)
+		res.Metrics.WitnessTime = time.Since(start)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		p.cache.add(key, w)
+	}
+
+	if err := task.ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+
+	proveStart := time.Now()
+	res.Proof, res.Err = proveWitness(p.ccs, p.pk, w)
+	res.Metrics.ProveTime = time.Since(proveStart)
+	return res
+}
+
+// witnessKey identifies the inputs of a job
+func witnessKey(job ProofJob) [sha256.Size]byte {
+	h := sha256.New()
+	write := func(v *big.Int) {
+		if v == nil {
+			h.Write([]byte{0})
+			return
+		}
+		fmt.Fprintf(h, "%x;", v)
+	}
+	write(job.Secret)
+	write(job.MerkleProof.Root)
+	for i := range job.MerkleProof.Path {
+		fmt.Fprintf(h, "%d;", job.MerkleProof.Path[i])
+		write(job.MerkleProof.Siblings[i])
+	}
+	write(job.SProof.Message)
+	write(job.SProof.Scope)
+	write(job.SProof.Nullifier)
+	fmt.Fprintf(h, "%d;", job.SProof.NullifierMode)
+	write(job.SProof.NullifierInput)
+	write(job.SProof.Timestamp)
+	if job.SProof.Domain != nil {
+		fmt.Fprintf(h, "%q;%d;%x;", job.SProof.Domain.AppID, job.SProof.Domain.ChainID, job.SProof.Domain.Verifier)
+	}
+
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// witnessCache is a bounded cache of witnesses, evicting the oldest entries first
+type witnessCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]witness.Witness
+	order   [][sha256.Size]byte
+}
+
+func newWitnessCache(size int) *witnessCache {
+	return &witnessCache{size: size, entries: make(map[[sha256.Size]byte]witness.Witness)}
+}
+
+func (c *witnessCache) get(key [sha256.Size]byte) (witness.Witness, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, ok := c.entries[key]
+	return w, ok
+}
+
+func (c *witnessCache) add(key [sha256.Size]byte, w witness.Witness) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	if len(c.order) == c.size {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = w
+	c.order = append(c.order, key)
+}
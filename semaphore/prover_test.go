@@ -0,0 +1,116 @@
+package semaphore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestJobs returns `n` proof jobs of random members of a group of `m` members
+func newTestJobs(t *testing.T, n, m int) (*Semaphore, []ProofJob) {
+	s := newTestSemaphore(t)
+	secrets := randomBigIntArray(m)
+	for i := 0; i < m; i++ {
+		idc, err := MimcHash([]*big.Int{secrets[i]})
+		require.NoError(t, err)
+		require.NoError(t, s.AddMember(idc))
+	}
+
+	jobs := []ProofJob{}
+	for i := 0; i < n; i++ {
+		idx := i % m
+		merkleProof, err := s.GenerateMerkleProof(idx)
+		require.NoError(t, err)
+		jobs = append(jobs, ProofJob{
+			Secret:      secrets[idx],
+			MerkleProof: merkleProof,
+			SProof:      randomSemaphoreProof(s.group.Root(), secrets[idx], t),
+		})
+	}
+	return s, jobs
+}
+
+// TestProver checks that the prover pool generates valid proofs in parallel
+// and reuses cached witnesses
+func TestProver(t *testing.T) {
+	s, jobs := newTestJobs(t, 6, 4)
+	p := NewProver(s.GetCss(), s.GetProvingKey(), ProverConfig{Workers: 3, CacheSize: 8})
+	defer p.Close()
+
+	// Submit all the jobs at once
+	results := []<-chan ProofResult{}
+	for _, job := range jobs {
+		results = append(results, p.Submit(context.Background(), job))
+	}
+	for i, result := range results {
+		res := <-result
+		require.NoError(t, res.Err)
+		require.False(t, res.Metrics.CachedWitness)
+		require.Positive(t, res.Metrics.ProveTime)
+		require.GreaterOrEqual(t, res.Metrics.Total, res.Metrics.QueueWait+res.Metrics.ProveTime)
+		require.NoError(t, VerifySemaphoreProof(s.GetVerifyingKey(), res.Proof, jobs[i].SProof))
+	}
+
+	// The same job reuses its witness
+	proof, metrics, err := p.Prove(context.Background(), jobs[0])
+	require.NoError(t, err)
+	require.True(t, metrics.CachedWitness)
+	require.Zero(t, metrics.WitnessTime)
+	require.NoError(t, VerifySemaphoreProof(s.GetVerifyingKey(), proof, jobs[0].SProof))
+
+	// Invalid jobs fail
+	badJob := jobs[1]
+	badJob.SProof.Nullifier = big.NewInt(0)
+	_, _, err = p.Prove(context.Background(), badJob)
+	require.Error(t, err)
+
+	// Malformed merkle proofs fail without reaching a worker
+	badJob = jobs[1]
+	badJob.MerkleProof.Siblings = badJob.MerkleProof.Siblings[:len(badJob.MerkleProof.Siblings)-1]
+	res := <-p.Submit(context.Background(), badJob)
+	require.Error(t, res.Err)
+	badJob = jobs[1]
+	badJob.MerkleProof.Path = make([]int, MAX_DEPTH+1)
+	badJob.MerkleProof.Siblings = randomBigIntArray(MAX_DEPTH + 1)
+	_, _, err = p.Prove(context.Background(), badJob)
+	require.Error(t, err)
+}
+
+// TestProverCancellation checks that cancelled and timed out jobs return promptly
+func TestProverCancellation(t *testing.T) {
+	s, jobs := newTestJobs(t, 4, 4)
+
+	// Cancelled before submission
+	p := NewProver(s.GetCss(), s.GetProvingKey(), ProverConfig{Workers: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := p.Prove(ctx, jobs[0])
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Timeout while the only worker is busy: the job returns without waiting for the worker
+	busy := p.Submit(context.Background(), jobs[1])
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, _, err = p.Prove(ctx, jobs[2])
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case <-busy:
+		t.Fatal("the timed out job waited for the busy worker")
+	default:
+	}
+	require.NoError(t, (<-busy).Err)
+
+	// Default timeout of the prover
+	timed := NewProver(s.GetCss(), s.GetProvingKey(), ProverConfig{Workers: 1, Timeout: time.Nanosecond})
+	_, _, err = timed.Prove(context.Background(), jobs[3])
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	timed.Close()
+
+	// Closed prover
+	p.Close()
+	_, _, err = p.Prove(context.Background(), jobs[0])
+	require.ErrorIs(t, err, ErrProverClosed)
+}
@@ -1,8 +1,11 @@
 package semaphore
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
 	"github.com/consensys/gnark/backend/groth16"
@@ -18,10 +21,18 @@ const (
 // Semaphore represents
 type Semaphore struct {
 	group      *leanIMT.LeanIMT
-	nullifiers map[*big.Int]bool
+	nullifiers map[string]bool
+	events     *EventLog
 	ccs        constraint.ConstraintSystem
 	vk         groth16.VerifyingKey
 	pk         groth16.ProvingKey
+
+	messageValidator func(*big.Int) bool
+	scopeValidator   func(*big.Int) bool
+	nullifierConfig  NullifierConfig
+	maxClockSkew     time.Duration
+	domain           *Domain
+	requireDomain    bool
 }
 
 type SemaphoreProof struct {
@@ -29,100 +40,338 @@ type SemaphoreProof struct {
 	Nullifier  *big.Int
 	Message    *big.Int
 	Scope      *big.Int
+
+	NullifierMode  NullifierMode // per scope by default
+	NullifierInput *big.Int      // the epoch or the app salt of the nullifier, unused per scope
+	Timestamp      *big.Int      // the unix time of the proof, nil if the circuit has no timestamp
+	Domain         *Domain       // the domain hashed into the scope of the circuit, nil if unbound
 }
 
 // NewSemaphore returns a new instance of semaphore and setup the Semaphore circuit
 func NewSemaphore() (*Semaphore, error) {
+	return NewSemaphoreContext(context.Background())
+}
+
+// NewSemaphoreContext returns a new instance of semaphore and setup the Semaphore circuit,
+// the setup is cancelled if `ctx` is done
+func NewSemaphoreContext(ctx context.Context) (*Semaphore, error) {
 	// Init lean IMT using Mimc Hash
 	imt, _ := leanIMT.NewLeanIMT(MimcHash, []*big.Int{})
 	s := &Semaphore{
 		group:      imt,
-		nullifiers: make(map[*big.Int]bool),
+		nullifiers: make(map[string]bool),
+		events:     NewEventLog(),
 	}
 
 	// Setup semaphore circuit
 	var err error
-	s.ccs, s.pk, s.vk, err = SetupCircuit()
+	s.ccs, s.pk, s.vk, err = SetupCircuitContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
+// NewSemaphoreWithKeys returns a new instance of semaphore using an already setup
+// Semaphore circuit, so that several groups can share the same keys
+func NewSemaphoreWithKeys(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	vk groth16.VerifyingKey,
+) *Semaphore {
+	// Init lean IMT using Mimc Hash
+	imt, _ := leanIMT.NewLeanIMT(MimcHash, []*big.Int{})
+	return &Semaphore{
+		group:      imt,
+		nullifiers: make(map[string]bool),
+		events:     NewEventLog(),
+		ccs:        ccs,
+		pk:         pk,
+		vk:         vk,
+	}
+}
+
 // AddMember inserts an identity commitment into the group
 func (s *Semaphore) AddMember(idc *big.Int) error {
-	return s.group.Insert(idc)
+	idx := s.group.Size()
+	if err := s.group.Insert(idc); err != nil {
+		return err
+	}
+	s.events.Append(GroupEvent{
+		Type:          EventMemberAdded,
+		Index:         idx,
+		NewCommitment: new(big.Int).Set(idc),
+		Root:          new(big.Int).Set(s.group.Root()),
+	})
+	return nil
+}
+
+// AddMembers inserts many identity commitments into the group
+func (s *Semaphore) AddMembers(idcs []*big.Int) error {
+	idx := s.group.Size()
+	if err := s.group.InsertMany(idcs); err != nil {
+		return err
+	}
+	copied := make([]*big.Int, len(idcs))
+	for i := range idcs {
+		copied[i] = new(big.Int).Set(idcs[i])
+	}
+	s.events.Append(GroupEvent{
+		Type:           EventMembersAdded,
+		Index:          idx,
+		NewCommitments: copied,
+		Root:           new(big.Int).Set(s.group.Root()),
+	})
+	return nil
 }
 
 // UpdateMember updates an identity commitment to a new one in the group
 func (s *Semaphore) UpdateMember(oldIdc, newIdc *big.Int) error {
-	idx := s.group.IndexOf(oldIdc)
-	if idx != -1 {
-		return s.group.Update(newIdc, idx)
-	} else {
-		return fmt.Errorf("the provided identity commitment doesn't exist")
+	idx := s.IndexOf(oldIdc)
+	if idx == -1 {
+		return ErrMemberNotFound
 	}
+	if err := s.group.Update(newIdc, idx); err != nil {
+		return err
+	}
+	s.events.Append(GroupEvent{
+		Type:          EventMemberUpdated,
+		Index:         idx,
+		OldCommitment: new(big.Int).Set(oldIdc),
+		NewCommitment: new(big.Int).Set(newIdc),
+		Root:          new(big.Int).Set(s.group.Root()),
+	})
+	return nil
 }
 
 // RemoveMember deletes an identity commitment from the group
 func (s *Semaphore) RemoveMember(idc *big.Int, path []*big.Int) error {
-	idx := s.group.IndexOf(idc)
-	if idx != -1 {
-		return s.group.Update(big.NewInt(0), idx)
-	} else {
-		return fmt.Errorf("the provided identity commitment doesn't exist")
+	idx := s.IndexOf(idc)
+	if idx == -1 {
+		return ErrMemberNotFound
 	}
+	if err := s.group.Update(big.NewInt(0), idx); err != nil {
+		return err
+	}
+	s.events.Append(GroupEvent{
+		Type:          EventMemberRemoved,
+		Index:         idx,
+		OldCommitment: new(big.Int).Set(idc),
+		Root:          new(big.Int).Set(s.group.Root()),
+	})
+	return nil
+}
+
+// GetEventLog returns the log of the changes of the group
+func (s *Semaphore) GetEventLog() *EventLog {
+	return s.events
 }
 
 // GenerateMerkleProof returns merkle proof at `idx` leaf of the group tree
 func (s *Semaphore) GenerateMerkleProof(idx int) (leanIMT.MerkleProof, error) {
+	if idx < 0 || idx >= s.group.Size() {
+		return leanIMT.MerkleProof{}, fmt.Errorf("invalid index")
+	}
 	return s.group.GenerateProof(idx)
 }
 
-// VerifyProof returns true if the provided proof is correct
-// and also prevents double signaling via the nullifier
+// IndexOf returns the index of an identity commitment in the group if it exists,
+// else return -1
+func (s *Semaphore) IndexOf(idc *big.Int) int {
+	if s.group.Size() == 0 {
+		return -1
+	}
+	return s.group.IndexOf(idc)
+}
+
+// IsNullifierUsed returns true if the nullifier has already been used by a verified proof
+func (s *Semaphore) IsNullifierUsed(nullifier *big.Int) bool {
+	return s.nullifiers[nullifier.String()]
+}
+
+// VerifyProof returns nil if the provided proof is correct
+// and also prevents double signaling via the nullifier.
+// Rejected proofs return a *VerificationError
 func (s *Semaphore) VerifyProof(proof *groth16_bn254.Proof, sProof SemaphoreProof) error {
+	return s.VerifyProofContext(context.Background(), proof, sProof)
+}
+
+// VerifyProofContext verifies a proof like VerifyProof, the nullifier is
+// left unused if `ctx` is done before the proof is verified
+func (s *Semaphore) VerifyProofContext(ctx context.Context, proof *groth16_bn254.Proof, sProof SemaphoreProof) error {
+	// Check that the signals are field elements, so that a nullifier has a single value
+	if err := checkSignals(sProof); err != nil {
+		return err
+	}
+
 	// Check Message and Scope
 	if !s.CheckMessage(sProof.Message) {
-		return fmt.Errorf("invalid message")
+		return newVerificationError(ReasonInvalidMessage, nil)
 	}
 	if !s.CheckScope(sProof.Scope) {
-		return fmt.Errorf("invalid scope")
+		return newVerificationError(ReasonInvalidScope, nil)
+	}
+
+	if s.requireDomain && s.domain == nil {
+		return newVerificationError(ReasonWrongDomain, fmt.Errorf("the instance isn't bound to a domain"))
+	}
+	if !sameDomain(sProof.Domain, s.domain) {
+		return newVerificationError(ReasonWrongDomain, nil)
+	}
+	if err := s.checkNullifierInput(sProof); err != nil {
+		return err
+	}
+
+	if err := s.checkTimestamp(sProof.Timestamp); err != nil {
+		return err
 	}
 
 	// Check if merkle root is correct
-	if sProof.MerkleRoot.Cmp(s.group.Root()) != 0 {
-		return fmt.Errorf("invalid merkle root")
+	if s.group.Size() == 0 || sProof.MerkleRoot == nil || sProof.MerkleRoot.Cmp(s.group.Root()) != 0 {
+		return newVerificationError(ReasonInvalidMerkleRoot, nil)
 	}
 
 	// Check if the provided nullifer is unused
-	if s.nullifiers[sProof.Nullifier] {
-		return fmt.Errorf("the provided nullifier is already used")
+	if s.nullifiers[sProof.Nullifier.String()] {
+		return newVerificationError(ReasonNullifierUsed, nil)
 	}
 
 	// Verify Proof
-	err := VerifySemaphoreProof(s.vk, proof, sProof)
+	err := VerifySemaphoreProofContext(ctx, s.vk, proof, sProof)
 	if err != nil {
-		return fmt.Errorf("failed to verify semaphore proof: %v", err)
+		return err
 	}
 
 	// Set the nullifier as used
-	s.nullifiers[sProof.Nullifier] = true
+	s.nullifiers[sProof.Nullifier.String()] = true
 
 	return nil
 }
 
-// TODO Implement CheckMessage logic
+// CheckMessage returns true if the message is accepted by the message validator,
+// any message is valid if no validator is set.
 // In real applications such as Voting, a valid message is one of the candidates
 func (s *Semaphore) CheckMessage(message *big.Int) bool {
-	return true
+	if message == nil {
+		return false
+	}
+	return s.messageValidator == nil || s.messageValidator(message)
 }
 
-// TODO implement CheckScope logic
+// CheckScope returns true if the scope is accepted by the scope validator,
+// any scope is valid if no validator is set.
 // In real applications such as Voting, the scope may be the identity of
 // the current round, or the big integer format of the title string "Town President Election", etc.
 func (s *Semaphore) CheckScope(scope *big.Int) bool {
-	return true
+	if scope == nil {
+		return false
+	}
+	return s.scopeValidator == nil || s.scopeValidator(scope)
+}
+
+// SetMessageValidator sets the function checking the messages of the verified proofs
+func (s *Semaphore) SetMessageValidator(validator func(message *big.Int) bool) {
+	s.messageValidator = validator
+}
+
+// SetScopeValidator sets the function checking the scopes of the verified proofs
+func (s *Semaphore) SetScopeValidator(validator func(scope *big.Int) bool) {
+	s.scopeValidator = validator
+}
+
+// SetNullifierConfig sets the derivation of the nullifiers of the accepted proofs, and the
+// epochs or the app salt their inputs must match. The keys of the instance must be those
+// of a circuit setup with the same mode
+func (s *Semaphore) SetNullifierConfig(cfg NullifierConfig) error {
+	if err := cfg.check(); err != nil {
+		return err
+	}
+	if cfg.Mode != NULLIFIER_PER_SCOPE && s.vk != nil && s.vk.NbPublicWitness() != NB_NULLIFIER_PUBLIC {
+		return fmt.Errorf("the keys of the instance aren't those of a %v circuit", cfg.Mode)
+	}
+	if cfg.AppSalt != nil {
+		cfg.AppSalt = new(big.Int).Set(cfg.AppSalt)
+	}
+	s.nullifierConfig = cfg
+	return nil
+}
+
+// checkNullifierInput returns a verification error if the nullifier of a proof isn't derived
+// with the mode of the instance, or if its input isn't an accepted epoch or the app salt
+func (s *Semaphore) checkNullifierInput(sProof SemaphoreProof) error {
+	cfg := s.nullifierConfig
+	if sProof.NullifierMode != cfg.Mode {
+		return newVerificationError(ReasonInvalidNullifierInput,
+			fmt.Errorf("the nullifier is %v, not %v", sProof.NullifierMode, cfg.Mode))
+	}
+	switch cfg.Mode {
+	case NULLIFIER_PER_EPOCH:
+		current, err := Epoch(time.Now(), cfg.EpochPeriod)
+		if err != nil {
+			return err
+		}
+		if sProof.NullifierInput == nil ||
+			new(big.Int).Sub(sProof.NullifierInput, current).CmpAbs(big.NewInt(cfg.EpochWindow)) > 0 {
+			return newVerificationError(ReasonInvalidNullifierInput,
+				fmt.Errorf("the epoch isn't within %d epochs of %v", cfg.EpochWindow, current))
+		}
+	case NULLIFIER_PER_APP:
+		if sProof.NullifierInput == nil || sProof.NullifierInput.Cmp(cfg.AppSalt) != 0 {
+			return newVerificationError(ReasonInvalidNullifierInput, fmt.Errorf("the app salt doesn't match"))
+		}
+	}
+	return nil
+}
+
+// SetDomain binds the instance to `domain`, it then only accepts the proofs generated
+// for this domain. The binding can't be removed, a domain must have an app id
+func (s *Semaphore) SetDomain(domain Domain) error {
+	if err := domain.check(); err != nil {
+		return err
+	}
+	domain.Verifier = bytes.Clone(domain.Verifier)
+	s.domain = &domain
+	return nil
+}
+
+// RequireDomain makes the domain of the instance mandatory: every proof is rejected
+// until it is bound with SetDomain, so that two unbound deployments sharing keys and
+// group roots can't accept each other's proofs. It can't be undone
+func (s *Semaphore) RequireDomain() {
+	s.requireDomain = true
+}
+
+// SetMaxClockSkew requires the accepted proofs to be timestamped within `skew` of the
+// current time, so that stored proofs can't be replayed later. The keys of the instance
+// must be those of a timestamped circuit, a zero skew disables the check
+func (s *Semaphore) SetMaxClockSkew(skew time.Duration) {
+	s.maxClockSkew = skew
+}
+
+// checkTimestamp returns a verification error if the timestamp
+// of a proof isn't within the maximum clock skew
+func (s *Semaphore) checkTimestamp(timestamp *big.Int) error {
+	if s.maxClockSkew <= 0 {
+		return nil
+	}
+	if timestamp == nil || !timestamp.IsInt64() {
+		return newVerificationError(ReasonStaleProof, fmt.Errorf("missing timestamp"))
+	}
+	skew := time.Since(time.Unix(timestamp.Int64(), 0)).Abs()
+	if skew > s.maxClockSkew {
+		return newVerificationError(ReasonStaleProof, fmt.Errorf("the clock skew is %v", skew.Round(time.Second)))
+	}
+	return nil
+}
+
+// GetGroup returns the lean IMT of the group
+func (s *Semaphore) GetGroup() *leanIMT.LeanIMT {
+	return s.group
+}
+
+// GetDomain returns the domain the instance is bound to, nil if unbound
+func (s *Semaphore) GetDomain() *Domain {
+	return s.domain
 }
 
 // GetCss returns the constraint system of the semaphore circuit
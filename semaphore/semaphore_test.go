@@ -1,10 +1,18 @@
 package semaphore
 
 import (
+	"context"
+	crand "crypto/rand"
 	"math/big"
 	"math/rand/v2"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,6 +48,65 @@ func randomSemaphoreProof(root *big.Int, secret *big.Int, t *testing.T) Semaphor
 	return sProof
 }
 
+var (
+	testKeysOnce sync.Once
+	testCcs      constraint.ConstraintSystem
+	testPk       groth16.ProvingKey
+	testVk       groth16.VerifyingKey
+	testKeysErr  error
+)
+
+// newTestSemaphore returns an empty semaphore group,
+// the circuit is only setup once for all the tests
+func newTestSemaphore(t testing.TB) *Semaphore {
+	testKeysOnce.Do(func() {
+		testCcs, testPk, testVk, testKeysErr = SetupCircuit()
+	})
+	require.NoError(t, testKeysErr)
+	return NewSemaphoreWithKeys(testCcs, testPk, testVk)
+}
+
+// newTestProof sets up a semaphore group of `n` random members, and returns
+// a groth16 proof generated by a random member with its semaphore proof
+func newTestProof(t *testing.T, n int) (*Semaphore, *groth16_bn254.Proof, SemaphoreProof) {
+	s := newTestSemaphore(t)
+
+	secrets := randomBigIntArray(n)
+	for i := 0; i < n; i++ {
+		idc, err := MimcHash([]*big.Int{secrets[i]})
+		require.NoError(t, err)
+		require.NoError(t, s.AddMember(idc))
+	}
+
+	idx := rand.IntN(n)
+	sProof := randomSemaphoreProof(s.group.Root(), secrets[idx], t)
+	merkleProof, err := s.GenerateMerkleProof(idx)
+	require.NoError(t, err)
+	proof, err := GenerateSemaphoreProof(s.GetCss(), s.GetProvingKey(), secrets[idx], merkleProof, sProof)
+	require.NoError(t, err)
+	return s, proof, sProof
+}
+
+// testSubgroupOrder is the order of the Baby Jubjub prime subgroup, identity.SUBGROUP_ORDER
+var testSubgroupOrder, _ = new(big.Int).SetString("2736030358979909402780800718157159386076813972158567259200215660948447373041", 10)
+
+// newTestMembers returns the secrets of `n` members and their identity commitments. The secrets
+// are drawn as identity.New does, the identity package can't be imported as it imports semaphore
+func newTestMembers(t testing.TB, n int) (secrets, idcs []*big.Int) {
+	for len(secrets) < n {
+		secret, err := crand.Int(crand.Reader, testSubgroupOrder)
+		require.NoError(t, err)
+		if secret.Sign() == 0 {
+			continue
+		}
+		idc, err := MimcHash([]*big.Int{secret})
+		require.NoError(t, err)
+		secrets = append(secrets, secret)
+		idcs = append(idcs, idc)
+	}
+	return secrets, idcs
+}
+
 func TestSemaphoreCircuit(t *testing.T) {
 	// Init semaphore group
 	s, err := NewSemaphore()
@@ -91,17 +158,17 @@ func TestSemaphoreCircuit(t *testing.T) {
 		Message:    big.NewInt(MAX_INT64 + 1), // invalid message
 	}
 	err = s.VerifyProof(proof, errSProof)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrInvalidProof) // CheckMessage accepts any message, the proof doesn't match
 
 	// Error Scope
 	errSProof = SemaphoreProof{
 		MerkleRoot: sProof.MerkleRoot,
 		Nullifier:  sProof.Nullifier,
-		Scope:      big.NewInt(MAX_INT64 + 1), // invalid message,
+		Scope:      big.NewInt(MAX_INT64 + 1), // invalid scope
 		Message:    sProof.Message,
 	}
 	err = s.VerifyProof(proof, errSProof)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrInvalidProof) // CheckScope accepts any scope, the proof doesn't match
 
 	// Error Root
 	errSProof = SemaphoreProof{
@@ -111,7 +178,34 @@ func TestSemaphoreCircuit(t *testing.T) {
 		Message:    sProof.Message,
 	}
 	err = s.VerifyProof(proof, errSProof)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrInvalidMerkleRoot)
+	errSProof.MerkleRoot = nil
+	err = s.VerifyProof(proof, errSProof)
+	require.ErrorIs(t, err, ErrInvalidMerkleRoot)
+
+	// Error Proof
+	errSProof = SemaphoreProof{
+		MerkleRoot: sProof.MerkleRoot,
+		Nullifier:  new(big.Int).Add(sProof.Nullifier, big.NewInt(1)),
+		Scope:      sProof.Scope,
+		Message:    sProof.Message,
+	}
+	err = s.VerifyProof(proof, errSProof)
+	require.ErrorIs(t, err, ErrInvalidProof)
+	var vErr *VerificationError
+	require.ErrorAs(t, err, &vErr)
+	require.Equal(t, ReasonInvalidProof, vErr.Reason)
+	require.Equal(t, "proof", vErr.Field)
+	require.NotNil(t, vErr.Err)
+	require.False(t, s.IsNullifierUsed(errSProof.Nullifier))
+
+	// Messages and scopes rejected by the validators
+	s.SetMessageValidator(func(m *big.Int) bool { return m.Cmp(sProof.Message) != 0 })
+	require.ErrorIs(t, s.VerifyProof(proof, sProof), ErrInvalidMessage)
+	s.SetMessageValidator(nil)
+	s.SetScopeValidator(func(scope *big.Int) bool { return scope.Cmp(sProof.Scope) != 0 })
+	require.ErrorIs(t, s.VerifyProof(proof, sProof), ErrInvalidScope)
+	s.SetScopeValidator(func(scope *big.Int) bool { return scope.Cmp(sProof.Scope) == 0 })
 
 	// Verify proof
 	err = s.VerifyProof(proof, sProof)
@@ -119,6 +213,86 @@ func TestSemaphoreCircuit(t *testing.T) {
 
 	// Error when double signaling
 	err = s.VerifyProof(proof, sProof)
-	require.Error(t, err)
-	require.ErrorContains(t, err, "the provided nullifier is already used")
+	require.ErrorIs(t, err, ErrNullifierUsed)
+	require.NotErrorIs(t, err, ErrInvalidProof)
+	require.ErrorAs(t, err, &vErr)
+	require.Equal(t, "nullifier", vErr.Field)
+}
+
+// TestAliasedSignals checks that signals out of the field are rejected, the witness reduces
+// them modulo r so that the nullifier plus r would verify again as a new nullifier
+func TestAliasedSignals(t *testing.T) {
+	s, proof, sProof := newTestProof(t, 3)
+	require.NoError(t, s.VerifyProof(proof, sProof))
+
+	r := fr.Modulus()
+	aliases := []struct {
+		field string
+		alias func(p *SemaphoreProof)
+	}{
+		{"nullifier", func(p *SemaphoreProof) { p.Nullifier = new(big.Int).Add(p.Nullifier, r) }},
+		{"nullifier", func(p *SemaphoreProof) { p.Nullifier = new(big.Int).Sub(p.Nullifier, r) }},
+		{"nullifier", func(p *SemaphoreProof) { p.Nullifier = new(big.Int).Add(p.Nullifier, new(big.Int).Lsh(r, 1)) }},
+		{"message", func(p *SemaphoreProof) { p.Message = new(big.Int).Add(p.Message, r) }},
+		{"scope", func(p *SemaphoreProof) { p.Scope = new(big.Int).Add(p.Scope, r) }},
+		{"merkleRoot", func(p *SemaphoreProof) { p.MerkleRoot = new(big.Int).Sub(p.MerkleRoot, r) }},
+	}
+	for _, a := range aliases {
+		aliased := sProof
+		a.alias(&aliased)
+		err := s.VerifyProof(proof, aliased)
+		require.Error(t, err)
+		var vErr *VerificationError
+		require.ErrorAs(t, err, &vErr)
+		require.Equal(t, a.field, vErr.Field)
+		require.Error(t, VerifySemaphoreProof(s.GetVerifyingKey(), proof, aliased))
+		invalid, err := BatchVerifySemaphoreProofs(s.GetVerifyingKey(), []*groth16_bn254.Proof{proof}, []SemaphoreProof{aliased})
+		require.NoError(t, err)
+		require.Equal(t, []int{0}, invalid)
+	}
+	require.False(t, s.IsNullifierUsed(new(big.Int).Add(sProof.Nullifier, r)))
+}
+
+// TestSemaphoreContext checks that setup, proving and verifying return promptly
+// once their context is done
+func TestSemaphoreContext(t *testing.T) {
+	// Setup
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := NewSemaphoreContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+
+	// Proving
+	s, proof, sProof := newTestProof(t, 5)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	merkleProof, err := s.GenerateMerkleProof(0)
+	require.NoError(t, err)
+	_, err = GenerateSemaphoreProofContext(cancelled, s.GetCss(), s.GetProvingKey(), big.NewInt(1), merkleProof, sProof)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Cancelled while proving: the call returns without waiting for the prover,
+	// which is held until the call has returned
+	proving, release := make(chan struct{}), make(chan struct{})
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		<-proving
+		cancel()
+	}()
+	_, err = runContext(ctx, func() (*groth16_bn254.Proof, error) {
+		close(proving)
+		<-release
+		return &groth16_bn254.Proof{}, nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	close(release)
+
+	// Verifying leaves the nullifier unused
+	err = s.VerifyProofContext(cancelled, proof, sProof)
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, s.IsNullifierUsed(sProof.Nullifier))
+	require.NoError(t, s.VerifyProofContext(context.Background(), proof, sProof))
+	require.True(t, s.IsNullifierUsed(sProof.Nullifier))
 }
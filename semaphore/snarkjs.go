@@ -0,0 +1,242 @@
+package semaphore
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// SNARKJS_PUBLIC_SIGNALS is the number of public signals of the Semaphore circuit,
+// in the order of `public.json`: message, scope, dummy square, merkle root, nullifier
+const SNARKJS_PUBLIC_SIGNALS = 5
+
+// SnarkJSVerifyingKey is the snarkjs `verification_key.json` format of a groth16 verifying key.
+// `vk_alphabeta_12` is omitted, snarkjs doesn't need it to verify proofs
+type SnarkJSVerifyingKey struct {
+	Protocol string       `json:"protocol"`
+	Curve    string       `json:"curve"`
+	NPublic  int          `json:"nPublic"`
+	Alpha1   [3]string    `json:"vk_alpha_1"`
+	Beta2    [3][2]string `json:"vk_beta_2"`
+	Gamma2   [3][2]string `json:"vk_gamma_2"`
+	Delta2   [3][2]string `json:"vk_delta_2"`
+	IC       [][3]string  `json:"IC"`
+}
+
+// SnarkJSProof is the snarkjs `proof.json` format of a groth16 proof
+type SnarkJSProof struct {
+	PiA      [3]string    `json:"pi_a"`
+	PiB      [3][2]string `json:"pi_b"`
+	PiC      [3]string    `json:"pi_c"`
+	Protocol string       `json:"protocol"`
+	Curve    string       `json:"curve"`
+}
+
+// ExportSnarkJSVerifyingKey converts a groth16 verifying key of the Semaphore circuit
+// into the snarkjs format
+func ExportSnarkJSVerifyingKey(vk groth16.VerifyingKey) (SnarkJSVerifyingKey, error) {
+	bvk, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return SnarkJSVerifyingKey{}, fmt.Errorf("the verifying key isn't a bn254 verifying key")
+	}
+	if len(bvk.CommitmentKeys) != 0 {
+		return SnarkJSVerifyingKey{}, fmt.Errorf("snarkjs doesn't support commitments")
+	}
+
+	res := SnarkJSVerifyingKey{
+		Protocol: "groth16",
+		Curve:    "bn128",
+		NPublic:  len(bvk.G1.K) - 1,
+		Alpha1:   formatG1(&bvk.G1.Alpha),
+		Beta2:    formatG2(&bvk.G2.Beta),
+		Gamma2:   formatG2(&bvk.G2.Gamma),
+		Delta2:   formatG2(&bvk.G2.Delta),
+	}
+	for i := range bvk.G1.K {
+		res.IC = append(res.IC, formatG1(&bvk.G1.K[i]))
+	}
+	return res, nil
+}
+
+// ImportSnarkJSVerifyingKey converts a verifying key in the snarkjs format into a groth16 verifying key
+func ImportSnarkJSVerifyingKey(svk SnarkJSVerifyingKey) (*groth16_bn254.VerifyingKey, error) {
+	if svk.Protocol != "groth16" || svk.Curve != "bn128" {
+		return nil, fmt.Errorf("unsupported protocol %q or curve %q", svk.Protocol, svk.Curve)
+	}
+	if svk.NPublic+1 != len(svk.IC) {
+		return nil, fmt.Errorf("expected %d IC points, got %d", svk.NPublic+1, len(svk.IC))
+	}
+
+	vk := new(groth16_bn254.VerifyingKey)
+	var err error
+	if vk.G1.Alpha, err = parseG1(svk.Alpha1); err != nil {
+		return nil, err
+	}
+	if vk.G2.Beta, err = parseG2(svk.Beta2); err != nil {
+		return nil, err
+	}
+	if vk.G2.Gamma, err = parseG2(svk.Gamma2); err != nil {
+		return nil, err
+	}
+	if vk.G2.Delta, err = parseG2(svk.Delta2); err != nil {
+		return nil, err
+	}
+	for _, ic := range svk.IC {
+		k, err := parseG1(ic)
+		if err != nil {
+			return nil, err
+		}
+		vk.G1.K = append(vk.G1.K, k)
+	}
+
+	if err := vk.Precompute(); err != nil {
+		return nil, err
+	}
+	return vk, nil
+}
+
+// ExportSnarkJSProof converts a groth16 proof into the snarkjs format
+func ExportSnarkJSProof(proof *groth16_bn254.Proof) SnarkJSProof {
+	return SnarkJSProof{
+		PiA:      formatG1(&proof.Ar),
+		PiB:      formatG2(&proof.Bs),
+		PiC:      formatG1(&proof.Krs),
+		Protocol: "groth16",
+		Curve:    "bn128",
+	}
+}
+
+// ImportSnarkJSProof converts a proof in the snarkjs format into a groth16 proof
+func ImportSnarkJSProof(sp SnarkJSProof) (*groth16_bn254.Proof, error) {
+	if sp.Protocol != "groth16" || sp.Curve != "bn128" {
+		return nil, fmt.Errorf("unsupported protocol %q or curve %q", sp.Protocol, sp.Curve)
+	}
+
+	proof := new(groth16_bn254.Proof)
+	var err error
+	if proof.Ar, err = parseG1(sp.PiA); err != nil {
+		return nil, err
+	}
+	if proof.Bs, err = parseG2(sp.PiB); err != nil {
+		return nil, err
+	}
+	if proof.Krs, err = parseG1(sp.PiC); err != nil {
+		return nil, err
+	}
+	if err := checkProofPoints(proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// ExportSnarkJSPublicSignals converts a semaphore proof into the snarkjs `public.json` format.
+// The scope of a proof bound to a domain is exported as the scope of the circuit
+func ExportSnarkJSPublicSignals(sProof SemaphoreProof) ([]string, error) {
+	if sProof.NullifierMode != NULLIFIER_PER_SCOPE || sProof.Timestamp != nil {
+		return nil, fmt.Errorf("only the proofs of the Semaphore circuit can be exported")
+	}
+	scope, err := circuitScope(sProof)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		sProof.Message.String(),
+		scope.String(),
+		snarkJSDummySquare(sProof.Message).String(),
+		sProof.MerkleRoot.String(),
+		sProof.Nullifier.String(),
+	}, nil
+}
+
+// ImportSnarkJSPublicSignals converts public signals in the snarkjs `public.json` format
+// into a semaphore proof. The domain of the proof isn't part of the signals, so the scope
+// of a proof bound to a domain is imported as the scope of the circuit, without a domain
+func ImportSnarkJSPublicSignals(signals []string) (SemaphoreProof, error) {
+	var sProof SemaphoreProof
+	if len(signals) != SNARKJS_PUBLIC_SIGNALS {
+		return sProof, fmt.Errorf("expected %d public signals, got %d", SNARKJS_PUBLIC_SIGNALS, len(signals))
+	}
+
+	vals := []*big.Int{}
+	for _, s := range signals {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok || v.Sign() < 0 || v.Cmp(bn254.ID.ScalarField()) >= 0 {
+			return sProof, fmt.Errorf("invalid public signal %q", s)
+		}
+		vals = append(vals, v)
+	}
+	sProof.Message, sProof.Scope, sProof.MerkleRoot, sProof.Nullifier = vals[0], vals[1], vals[3], vals[4]
+
+	// The dummy square is recomputed by the verifier, so it must be consistent
+	if snarkJSDummySquare(sProof.Message).Cmp(vals[2]) != 0 {
+		return sProof, fmt.Errorf("invalid dummy square")
+	}
+	return sProof, nil
+}
+
+// snarkJSDummySquare returns the dummy square of `message`, reduced modulo r
+func snarkJSDummySquare(message *big.Int) *big.Int {
+	dummySquare := new(big.Int).Mul(message, message)
+	return dummySquare.Mod(dummySquare, bn254.ID.ScalarField())
+}
+
+// formatG1 formats a G1 point as snarkjs projective coordinates
+func formatG1(p *bn254.G1Affine) [3]string {
+	return [3]string{p.X.String(), p.Y.String(), "1"}
+}
+
+// formatG2 formats a G2 point as snarkjs projective coordinates
+func formatG2(p *bn254.G2Affine) [3][2]string {
+	return [3][2]string{
+		{p.X.A0.String(), p.X.A1.String()},
+		{p.Y.A0.String(), p.Y.A1.String()},
+		{"1", "0"},
+	}
+}
+
+// parseG1 parses a G1 point from snarkjs projective coordinates
+func parseG1(coords [3]string) (bn254.G1Affine, error) {
+	var p bn254.G1Affine
+	if coords[2] != "1" {
+		return p, fmt.Errorf("unsupported G1 point %v", coords)
+	}
+	var err error
+	if p.X, err = parseCoordinate(coords[0]); err != nil {
+		return p, err
+	}
+	if p.Y, err = parseCoordinate(coords[1]); err != nil {
+		return p, err
+	}
+	if !p.IsOnCurve() {
+		return p, fmt.Errorf("the G1 point %v isn't on the curve", coords)
+	}
+	return p, nil
+}
+
+// parseG2 parses a G2 point from snarkjs projective coordinates
+func parseG2(coords [3][2]string) (bn254.G2Affine, error) {
+	var p bn254.G2Affine
+	if coords[2] != [2]string{"1", "0"} {
+		return p, fmt.Errorf("unsupported G2 point %v", coords)
+	}
+	var err error
+	if p.X.A0, err = parseCoordinate(coords[0][0]); err != nil {
+		return p, err
+	}
+	if p.X.A1, err = parseCoordinate(coords[0][1]); err != nil {
+		return p, err
+	}
+	if p.Y.A0, err = parseCoordinate(coords[1][0]); err != nil {
+		return p, err
+	}
+	if p.Y.A1, err = parseCoordinate(coords[1][1]); err != nil {
+		return p, err
+	}
+	if !p.IsOnCurve() || !p.IsInSubGroup() {
+		return p, fmt.Errorf("the G2 point %v isn't in the subgroup", coords)
+	}
+	return p, nil
+}
@@ -0,0 +1,77 @@
+package semaphore
+
+import (
+	"encoding/json"
+	"testing"
+
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnarkJS checks that verifying keys, proofs and public signals exported to the
+// snarkjs format and imported back still verify
+func TestSnarkJS(t *testing.T) {
+	s, proof, sProof := newTestProof(t, 5)
+
+	// Verifying key round trip through JSON
+	svk, err := ExportSnarkJSVerifyingKey(s.GetVerifyingKey())
+	require.NoError(t, err)
+	require.Equal(t, SNARKJS_PUBLIC_SIGNALS, svk.NPublic)
+	data, err := json.Marshal(svk)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"vk_alpha_1"`)
+	var decodedVk SnarkJSVerifyingKey
+	require.NoError(t, json.Unmarshal(data, &decodedVk))
+	vk, err := ImportSnarkJSVerifyingKey(decodedVk)
+	require.NoError(t, err)
+
+	// The imported verifying key matches the original one
+	// (except G1.Beta and G1.Delta, which are unused by gnark and unknown to snarkjs)
+	bvk := s.GetVerifyingKey().(*groth16_bn254.VerifyingKey)
+	require.Equal(t, bvk.G1.Alpha, vk.G1.Alpha)
+	require.Equal(t, bvk.G1.K, vk.G1.K)
+	require.Equal(t, bvk.G2.Beta, vk.G2.Beta)
+	require.Equal(t, bvk.G2.Gamma, vk.G2.Gamma)
+	require.Equal(t, bvk.G2.Delta, vk.G2.Delta)
+
+	// Proof and public signals round trip through JSON
+	data, err = json.Marshal(ExportSnarkJSProof(proof))
+	require.NoError(t, err)
+	var decodedProof SnarkJSProof
+	require.NoError(t, json.Unmarshal(data, &decodedProof))
+	proof2, err := ImportSnarkJSProof(decodedProof)
+	require.NoError(t, err)
+
+	exported, err := ExportSnarkJSPublicSignals(sProof)
+	require.NoError(t, err)
+	data, err = json.Marshal(exported)
+	require.NoError(t, err)
+	var signals []string
+	require.NoError(t, json.Unmarshal(data, &signals))
+	sProof2, err := ImportSnarkJSPublicSignals(signals)
+	require.NoError(t, err)
+	require.Equal(t, sProof, sProof2)
+
+	require.NoError(t, VerifySemaphoreProof(vk, proof2, sProof2))
+
+	// Inconsistent or invalid values are rejected
+	signals[2] = "1"
+	_, err = ImportSnarkJSPublicSignals(signals)
+	require.Error(t, err)
+	_, err = ImportSnarkJSPublicSignals(signals[:4])
+	require.Error(t, err)
+
+	badProof := decodedProof
+	badProof.PiB[0][0], badProof.PiB[0][1] = badProof.PiB[0][1], badProof.PiB[0][0]
+	_, err = ImportSnarkJSProof(badProof)
+	require.Error(t, err)
+	badProof = decodedProof
+	badProof.Curve = "bls12381"
+	_, err = ImportSnarkJSProof(badProof)
+	require.Error(t, err)
+
+	badVk := decodedVk
+	badVk.IC = badVk.IC[1:]
+	_, err = ImportSnarkJSVerifyingKey(badVk)
+	require.Error(t, err)
+}
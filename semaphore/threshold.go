@@ -0,0 +1,188 @@
+package semaphore
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// Errors of the threshold collectors, use errors.Is to check them
+var (
+	ErrDuplicateContribution  = errors.New("the member has already contributed")
+	ErrInvalidContribution    = errors.New("the contribution isn't an endorsement of the message by a member of the group")
+	ErrNotEnoughContributions = errors.New("not enough contributions")
+)
+
+// ThresholdProof is the endorsement of a message in a scope by k distinct members of a group,
+// made of their own semaphore proofs sorted by nullifier. The nullifiers are distinct, so the
+// members are, and no member shares its secret. The proofs can also be aggregated into a
+// single one with AggregateProofs
+type ThresholdProof struct {
+	MerkleRoot *big.Int
+	Message    *big.Int
+	Scope      *big.Int
+	Nullifiers []*big.Int
+	Proofs     []*groth16_bn254.Proof
+	Domain     *Domain // the domain hashed into the scope of the circuit, nil if unbound
+}
+
+// Contribution is the endorsement of a member: its semaphore proof of the
+// message in the scope, generated with the keys of the Semaphore circuit
+type Contribution struct {
+	Proof          *groth16_bn254.Proof
+	SemaphoreProof SemaphoreProof
+}
+
+// ThresholdCollector collects the contributions of the members endorsing a message,
+// until there are enough of them to make a threshold proof
+type ThresholdCollector struct {
+	mu            sync.Mutex
+	k             int
+	vk            groth16.VerifyingKey
+	root          *big.Int
+	message       *big.Int
+	scope         *big.Int
+	domain        *Domain
+	contributions []Contribution
+	nullifiers    map[string]bool
+}
+
+// NewThresholdCollector returns a collector of `k` contributions endorsing `message` in `scope`,
+// against the group of root `root`. The contributions are verified with `vk`, the verifying key
+// of the Semaphore circuit, and bound to `domain`, or unbound if it is nil
+func NewThresholdCollector(k int, vk groth16.VerifyingKey, root, message, scope *big.Int, domain *Domain) (*ThresholdCollector, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("the threshold must be at least 1")
+	}
+	if vk == nil || root == nil || message == nil || scope == nil {
+		return nil, fmt.Errorf("missing verifying key, root, message or scope")
+	}
+	if domain != nil {
+		if err := domain.check(); err != nil {
+			return nil, err
+		}
+	}
+	return &ThresholdCollector{
+		k:          k,
+		vk:         vk,
+		root:       new(big.Int).Set(root),
+		message:    new(big.Int).Set(message),
+		scope:      new(big.Int).Set(scope),
+		domain:     domain,
+		nullifiers: make(map[string]bool),
+	}, nil
+}
+
+// Add verifies and adds the contribution of a member, contributions
+// beyond the threshold are ignored
+func (c *ThresholdCollector) Add(contribution Contribution) error {
+	sProof := contribution.SemaphoreProof
+	if contribution.Proof == nil || sProof.NullifierMode != NULLIFIER_PER_SCOPE || sProof.Timestamp != nil ||
+		sProof.MerkleRoot == nil || sProof.MerkleRoot.Cmp(c.root) != 0 ||
+		sProof.Message == nil || sProof.Message.Cmp(c.message) != 0 ||
+		sProof.Scope == nil || sProof.Scope.Cmp(c.scope) != 0 || !sameDomain(sProof.Domain, c.domain) {
+		return ErrInvalidContribution
+	}
+	if err := VerifySemaphoreProof(c.vk, contribution.Proof, sProof); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidContribution, err)
+	}
+
+	// The nullifier of a member is the same in every contribution
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nullifiers[sProof.Nullifier.String()] {
+		return ErrDuplicateContribution
+	}
+	if len(c.contributions) < c.k {
+		c.nullifiers[sProof.Nullifier.String()] = true
+		c.contributions = append(c.contributions, contribution)
+	}
+	return nil
+}
+
+// Count returns the number of contributions
+func (c *ThresholdCollector) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.contributions)
+}
+
+// Ready returns true once the collector has enough contributions
+func (c *ThresholdCollector) Ready() bool {
+	return c.Count() == c.k
+}
+
+// Proof returns the threshold proof of the contributions, sorted by nullifier
+// so that the order of the contributions isn't revealed
+func (c *ThresholdCollector) Proof() (ThresholdProof, error) {
+	c.mu.Lock()
+	contributions := slices.Clone(c.contributions)
+	c.mu.Unlock()
+	if len(contributions) < c.k {
+		return ThresholdProof{}, fmt.Errorf("%w: %d of %d", ErrNotEnoughContributions, len(contributions), c.k)
+	}
+	slices.SortFunc(contributions, func(a, b Contribution) int {
+		return a.SemaphoreProof.Nullifier.Cmp(b.SemaphoreProof.Nullifier)
+	})
+
+	tProof := ThresholdProof{
+		MerkleRoot: c.root,
+		Message:    c.message,
+		Scope:      c.scope,
+		Domain:     c.domain,
+	}
+	for _, contribution := range contributions {
+		tProof.Nullifiers = append(tProof.Nullifiers, contribution.SemaphoreProof.Nullifier)
+		tProof.Proofs = append(tProof.Proofs, contribution.Proof)
+	}
+	return tProof, nil
+}
+
+// SemaphoreProofs returns the semaphore proofs of the members, e.g. to aggregate them
+func (tProof ThresholdProof) SemaphoreProofs() []SemaphoreProof {
+	sProofs := []SemaphoreProof{}
+	for _, nullifier := range tProof.Nullifiers {
+		sProofs = append(sProofs, SemaphoreProof{
+			MerkleRoot: tProof.MerkleRoot,
+			Nullifier:  nullifier,
+			Message:    tProof.Message,
+			Scope:      tProof.Scope,
+			Domain:     tProof.Domain,
+		})
+	}
+	return sProofs
+}
+
+// VerifyThresholdProof returns nil if the threshold proof holds the valid proofs of at least `k`
+// distinct members, `vk` is the verifying key of the Semaphore circuit. The nullifiers
+// still have to be checked against the used ones
+func VerifyThresholdProof(vk groth16.VerifyingKey, tProof ThresholdProof, k int) error {
+	if len(tProof.Nullifiers) < k || len(tProof.Proofs) != len(tProof.Nullifiers) {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("%w: %d of %d", ErrNotEnoughContributions, len(tProof.Proofs), k))
+	}
+	sProofs := tProof.SemaphoreProofs()
+	for i, sProof := range sProofs {
+		if err := checkSignals(sProof); err != nil {
+			return err
+		}
+		if tProof.Proofs[i] == nil {
+			return newVerificationError(ReasonInvalidProof, fmt.Errorf("missing proof %d", i))
+		}
+		if i > 0 && sProof.Nullifier.Cmp(sProofs[i-1].Nullifier) <= 0 {
+			return newFieldError(ReasonInvalidProof, "nullifier", fmt.Errorf("the nullifiers must be distinct, sorted in increasing order"))
+		}
+	}
+	invalid, err := BatchVerifySemaphoreProofs(vk, tProof.Proofs, sProofs)
+	if err != nil {
+		return newVerificationError(ReasonInvalidProof, err)
+	}
+	if len(invalid) > 0 {
+		return newVerificationError(ReasonInvalidProof, fmt.Errorf("the proofs %v are invalid", invalid))
+	}
+	return nil
+}
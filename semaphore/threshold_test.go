@@ -0,0 +1,105 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+// TestThresholdProof checks collecting the endorsements of 3 members and the threshold proof
+func TestThresholdProof(t *testing.T) {
+	k := 3
+	s := newTestSemaphore(t)
+	secrets, idcs := newTestMembers(t, 5)
+	require.NoError(t, s.AddMembers(idcs))
+	message, scope := big.NewInt(42), big.NewInt(2024)
+	// Each member proves its own endorsement, the secrets aren't shared
+	contribution := func(i int, domain *Domain) Contribution {
+		boundScope, err := circuitScope(SemaphoreProof{Scope: scope, Domain: domain})
+		require.NoError(t, err)
+		nullifier, err := MimcHash([]*big.Int{boundScope, secrets[i]})
+		require.NoError(t, err)
+		merkleProof, err := s.GenerateMerkleProof(i)
+		require.NoError(t, err)
+		sProof := SemaphoreProof{MerkleRoot: merkleProof.Root, Nullifier: nullifier, Message: message, Scope: scope, Domain: domain}
+		proof, err := GenerateSemaphoreProof(s.GetCss(), s.GetProvingKey(), secrets[i], merkleProof, sProof)
+		require.NoError(t, err)
+		return Contribution{Proof: proof, SemaphoreProof: sProof}
+	}
+	contributions := map[int]Contribution{}
+	for _, i := range []int{0, 3, 4} {
+		contributions[i] = contribution(i, nil)
+	}
+
+	c, err := NewThresholdCollector(k, s.GetVerifyingKey(), s.GetGroup().Root(), message, scope, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Add(contributions[3]))
+	require.ErrorIs(t, c.Add(contributions[3]), ErrDuplicateContribution)
+
+	// Endorsements of other messages and forged proofs are rejected
+	other := contributions[0]
+	other.SemaphoreProof.Message = big.NewInt(43)
+	require.ErrorIs(t, c.Add(other), ErrInvalidContribution)
+	forged := contributions[0]
+	forged.Proof = contributions[4].Proof
+	require.ErrorIs(t, c.Add(forged), ErrInvalidContribution)
+	require.ErrorIs(t, c.Add(contribution(1, &Domain{AppID: "petition"})), ErrInvalidContribution)
+
+	require.NoError(t, c.Add(contributions[0]))
+	_, err = c.Proof()
+	require.ErrorIs(t, err, ErrNotEnoughContributions)
+	require.False(t, c.Ready())
+	require.NoError(t, c.Add(contributions[4]))
+	require.True(t, c.Ready())
+
+	tProof, err := c.Proof()
+	require.NoError(t, err)
+	require.Len(t, tProof.Nullifiers, k)
+	require.NoError(t, VerifyThresholdProof(s.GetVerifyingKey(), tProof, k))
+	require.Error(t, VerifyThresholdProof(s.GetVerifyingKey(), tProof, k+1))
+	for _, i := range []int{0, 3, 4} {
+		require.Contains(t, tProof.Nullifiers, contributions[i].SemaphoreProof.Nullifier)
+	}
+
+	// Tampered proofs
+	bad := tProof
+	bad.Message = big.NewInt(43)
+	require.ErrorIs(t, VerifyThresholdProof(s.GetVerifyingKey(), bad, k), ErrInvalidProof)
+	bad = tProof
+	bad.Nullifiers = []*big.Int{tProof.Nullifiers[1], tProof.Nullifiers[0], tProof.Nullifiers[2]}
+	require.ErrorIs(t, VerifyThresholdProof(s.GetVerifyingKey(), bad, k), ErrInvalidProof)
+	bad = tProof
+	bad.Proofs = []*groth16_bn254.Proof{tProof.Proofs[1], tProof.Proofs[0], tProof.Proofs[2]}
+	require.ErrorIs(t, VerifyThresholdProof(s.GetVerifyingKey(), bad, k), ErrInvalidProof)
+	bad.Proofs = []*groth16_bn254.Proof{tProof.Proofs[0], nil, tProof.Proofs[2]}
+	require.ErrorIs(t, VerifyThresholdProof(s.GetVerifyingKey(), bad, k), ErrInvalidProof)
+	bad = tProof
+	bad.Nullifiers = []*big.Int{tProof.Nullifiers[0], tProof.Nullifiers[1], new(big.Int).Add(tProof.Nullifiers[2], fr.Modulus())}
+	var vErr *VerificationError
+	require.ErrorAs(t, VerifyThresholdProof(s.GetVerifyingKey(), bad, k), &vErr)
+	require.Equal(t, "nullifier", vErr.Field)
+
+	// The same member can't endorse twice
+	bad = tProof
+	bad.Nullifiers = []*big.Int{tProof.Nullifiers[0], tProof.Nullifiers[0], tProof.Nullifiers[2]}
+	bad.Proofs = []*groth16_bn254.Proof{tProof.Proofs[0], tProof.Proofs[0], tProof.Proofs[2]}
+	require.ErrorIs(t, VerifyThresholdProof(s.GetVerifyingKey(), bad, k), ErrInvalidProof)
+
+	// A proof bound to a domain has other nullifiers, and isn't valid without its domain
+	domain := &Domain{AppID: "petition", ChainID: 1}
+	c, err = NewThresholdCollector(k, s.GetVerifyingKey(), s.GetGroup().Root(), message, scope, domain)
+	require.NoError(t, err)
+	for _, i := range []int{0, 3, 4} {
+		require.NoError(t, c.Add(contribution(i, domain)))
+	}
+	tProof, err = c.Proof()
+	require.NoError(t, err)
+	require.NoError(t, VerifyThresholdProof(s.GetVerifyingKey(), tProof, k))
+	require.NotContains(t, tProof.Nullifiers, contributions[0].SemaphoreProof.Nullifier)
+	bad = tProof
+	bad.Domain = nil
+	require.ErrorIs(t, VerifyThresholdProof(s.GetVerifyingKey(), bad, k), ErrInvalidProof)
+}
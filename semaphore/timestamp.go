@@ -0,0 +1,44 @@
+package semaphore
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+)
+
+// SetupTimestampedCircuit performs the setup phase of the Semaphore circuit
+// with the timestamp of the proof as a public input
+func SetupTimestampedCircuit() (
+	constraint.ConstraintSystem,
+	groth16.ProvingKey,
+	groth16.VerifyingKey,
+	error,
+) {
+	return setupCircuitContext(context.Background(), &circuits.SemaphoreTimestamped{})
+}
+
+// Timestamp returns the timestamp of a proof generated at `t`
+func Timestamp(t time.Time) *big.Int {
+	return big.NewInt(t.Unix())
+}
+
+// timestampAssignment returns the assignment of the timestamped circuit
+// of a semaphore proof from the assignment `s` of the Semaphore circuit
+func timestampAssignment(s *circuits.Semaphore, sProof SemaphoreProof) (*circuits.SemaphoreTimestamped, error) {
+	return &circuits.SemaphoreTimestamped{
+		Secret:              s.Secret,
+		MerkleProofLength:   s.MerkleProofLength,
+		MerkleProofIndices:  s.MerkleProofIndices,
+		MerkleProofSiblings: s.MerkleProofSiblings,
+		Message:             s.Message,
+		Scope:               s.Scope,
+		DummySquare:         s.DummySquare,
+		MerkleRoot:          s.MerkleRoot,
+		Nullifier:           s.Nullifier,
+		Timestamp:           sProof.Timestamp,
+	}, nil
+}
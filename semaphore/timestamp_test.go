@@ -0,0 +1,73 @@
+package semaphore
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/circuits"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTimestampedProof checks that the timestamp is bound to the proof,
+// and that stale proofs are rejected
+func TestTimestampedProof(t *testing.T) {
+	ccs, pk, vk, err := SetupTimestampedCircuit()
+	require.NoError(t, err)
+	s := NewSemaphoreWithKeys(ccs, pk, vk)
+	s.SetMaxClockSkew(5 * time.Minute)
+
+	secrets := randomBigIntArray(3)
+	for _, secret := range secrets {
+		idc, err := MimcHash([]*big.Int{secret})
+		require.NoError(t, err)
+		require.NoError(t, s.AddMember(idc))
+	}
+	prove := func(i int, at time.Time) (*SemaphoreProof, error) {
+		merkleProof, err := s.GenerateMerkleProof(i)
+		require.NoError(t, err)
+		sProof := randomSemaphoreProof(s.GetGroup().Root(), secrets[i], t)
+		sProof.Timestamp = Timestamp(at)
+		proof, err := GenerateSemaphoreProof(ccs, pk, secrets[i], merkleProof, sProof)
+		require.NoError(t, err)
+		return &sProof, s.VerifyProof(proof, sProof)
+	}
+
+	// Fresh proofs, within the clock skew
+	_, err = prove(0, time.Now())
+	require.NoError(t, err)
+	_, err = prove(1, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	// Stale proofs, their nullifiers are left unused
+	sProof, err := prove(2, time.Now().Add(-time.Hour))
+	require.ErrorIs(t, err, ErrStaleProof)
+	require.False(t, s.IsNullifierUsed(sProof.Nullifier))
+	_, err = prove(2, time.Now().Add(time.Hour))
+	require.ErrorIs(t, err, ErrStaleProof)
+
+	// The timestamp can't be changed
+	merkleProof, err := s.GenerateMerkleProof(2)
+	require.NoError(t, err)
+	old := randomSemaphoreProof(s.GetGroup().Root(), secrets[2], t)
+	old.Timestamp = Timestamp(time.Now().Add(-time.Hour))
+	proof, err := GenerateSemaphoreProof(ccs, pk, secrets[2], merkleProof, old)
+	require.NoError(t, err)
+	old.Timestamp = Timestamp(time.Now())
+	require.ErrorIs(t, s.VerifyProof(proof, old), ErrInvalidProof)
+	old.Timestamp = nil
+	require.ErrorIs(t, s.VerifyProof(proof, old), ErrStaleProof)
+
+	// The timestamp must fit in 64 bits
+	fresh := randomSemaphoreProof(s.GetGroup().Root(), secrets[2], t)
+	fresh.Timestamp = Timestamp(time.Now())
+	base, err := semaphoreAssignment(secrets[2], merkleProof, fresh)
+	require.NoError(t, err)
+	assignment, err := timestampAssignment(base, fresh)
+	require.NoError(t, err)
+	require.NoError(t, test.IsSolved(&circuits.SemaphoreTimestamped{}, assignment, ecc.BN254.ScalarField()))
+	assignment.Timestamp = new(big.Int).Lsh(big.NewInt(1), 64)
+	require.Error(t, test.IsSolved(&circuits.SemaphoreTimestamped{}, assignment, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,359 @@
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+)
+
+// MAX_BODY_SIZE limits the size of request bodies
+const MAX_BODY_SIZE = 1 << 20
+
+// group is a semaphore group guarded by its own lock
+type group struct {
+	mu sync.Mutex
+	s  *semaphore.Semaphore
+}
+
+// Server exposes semaphore groups and proof verification through a JSON HTTP API.
+// All groups share the same circuit and keys
+type Server struct {
+	mu     sync.RWMutex
+	groups map[string]*group
+	ccs    constraint.ConstraintSystem
+	pk     groth16.ProvingKey
+	vk     groth16.VerifyingKey
+}
+
+// NewServer returns a new server using an already setup Semaphore circuit
+func NewServer(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey) *Server {
+	return &Server{
+		groups: make(map[string]*group),
+		ccs:    ccs,
+		pk:     pk,
+		vk:     vk,
+	}
+}
+
+// Handler returns the HTTP handler of the API
+//
+//	POST   /groups                               create a group
+//	GET    /groups/{id}                          get the size, depth and root of a group
+//	GET    /groups/{id}/root                     get the root of a group
+//	POST   /groups/{id}/members                  add a member
+//	PUT    /groups/{id}/members/{commitment}     update a member
+//	DELETE /groups/{id}/members/{commitment}     remove a member
+//	GET    /groups/{id}/members/{index}/proof    get the merkle proof of a member
+//	POST   /groups/{id}/proofs                   verify a semaphore proof
+//	GET    /groups/{id}/events?since={seq}       get the membership events of a group
+//	GET    /verifying-key                        get the groth16 verifying key
+func (srv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /groups", srv.createGroup)
+	mux.HandleFunc("GET /groups/{id}", srv.withGroup(srv.getGroup))
+	mux.HandleFunc("GET /groups/{id}/root", srv.withGroup(srv.getRoot))
+	mux.HandleFunc("POST /groups/{id}/members", srv.withGroup(srv.addMember))
+	mux.HandleFunc("PUT /groups/{id}/members/{commitment}", srv.withGroup(srv.updateMember))
+	mux.HandleFunc("DELETE /groups/{id}/members/{commitment}", srv.withGroup(srv.removeMember))
+	mux.HandleFunc("GET /groups/{id}/members/{index}/proof", srv.withGroup(srv.getMerkleProof))
+	mux.HandleFunc("POST /groups/{id}/proofs", srv.withGroup(srv.verifyProof))
+	mux.HandleFunc("GET /groups/{id}/events", srv.withGroup(srv.getEvents))
+	mux.HandleFunc("GET /verifying-key", srv.getVerifyingKey)
+	return mux
+}
+
+// withGroup resolves the {id} path value into a locked group
+func (srv *Server) withGroup(h func(http.ResponseWriter, *http.Request, string, *semaphore.Semaphore)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		srv.mu.RLock()
+		g, ok := srv.groups[id]
+		srv.mu.RUnlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("group %q doesn't exist", id))
+			return
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		h(w, r, id, g.s)
+	}
+}
+
+func (srv *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	var req CreateGroupRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.ID == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing id"))
+		return
+	}
+
+	s := semaphore.NewSemaphoreWithKeys(srv.ccs, srv.pk, srv.vk)
+	if req.Domain != nil {
+		if err := s.SetDomain(*req.Domain); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	for _, m := range req.Members {
+		idc, err := parseCommitment(m)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if s.IndexOf(idc) != -1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("duplicated member %s", m))
+			return
+		}
+		if err := s.AddMember(idc); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if _, ok := srv.groups[req.ID]; ok {
+		writeError(w, http.StatusConflict, fmt.Errorf("group %q already exists", req.ID))
+		return
+	}
+	srv.groups[req.ID] = &group{s: s}
+	writeJSON(w, http.StatusCreated, groupResponse(req.ID, s))
+}
+
+func (srv *Server) getGroup(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	writeJSON(w, http.StatusOK, groupResponse(id, s))
+}
+
+func (srv *Server) getRoot(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	if s.GetGroup().Size() == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("group %q is empty", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, groupResponse(id, s))
+}
+
+func (srv *Server) addMember(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	var req MemberRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	idc, err := parseCommitment(req.Commitment)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if s.IndexOf(idc) != -1 {
+		writeError(w, http.StatusConflict, fmt.Errorf("the provided identity commitment is already a member"))
+		return
+	}
+
+	if err := s.AddMember(idc); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, memberResponse(s, idc))
+}
+
+func (srv *Server) updateMember(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	oldIdc, err := parseCommitment(r.PathValue("commitment"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var req MemberRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	newIdc, err := parseCommitment(req.Commitment)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if s.IndexOf(oldIdc) == -1 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("the provided identity commitment doesn't exist"))
+		return
+	}
+	if s.IndexOf(newIdc) != -1 {
+		writeError(w, http.StatusConflict, fmt.Errorf("the new identity commitment is already a member"))
+		return
+	}
+
+	if err := s.UpdateMember(oldIdc, newIdc); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, memberResponse(s, newIdc))
+}
+
+func (srv *Server) removeMember(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	idc, err := parseCommitment(r.PathValue("commitment"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	idx := s.IndexOf(idc)
+	if idx == -1 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("the provided identity commitment doesn't exist"))
+		return
+	}
+
+	if err := s.RemoveMember(idc, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MemberResponse{
+		Index:      idx,
+		Commitment: "0",
+		Root:       s.GetGroup().Root().String(),
+	})
+}
+
+func (srv *Server) getMerkleProof(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	idx, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid index"))
+		return
+	}
+	merkleProof, err := s.GenerateMerkleProof(idx)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	siblings := []string{}
+	for _, sibling := range merkleProof.Siblings {
+		siblings = append(siblings, sibling.String())
+	}
+	path := merkleProof.Path
+	if path == nil {
+		path = []int{}
+	}
+	writeJSON(w, http.StatusOK, MerkleProofResponse{
+		Index:    idx,
+		Leaf:     merkleProof.Node.String(),
+		Root:     merkleProof.Root.String(),
+		Path:     path,
+		Siblings: siblings,
+	})
+}
+
+func (srv *Server) verifyProof(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	var req ProofRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	proof, sProof, err := req.Decode()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.VerifyProof(proof, sProof); err != nil {
+		writeError(w, verificationStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ProofResponse{
+		Valid:     true,
+		Nullifier: sProof.Nullifier.String(),
+	})
+}
+
+func (srv *Server) getEvents(w http.ResponseWriter, r *http.Request, id string, s *semaphore.Semaphore) {
+	since := uint64(0)
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		since, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since"))
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.GetEventLog().Since(since))
+}
+
+func (srv *Server) getVerifyingKey(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if _, err := srv.vk.WriteTo(&buf); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, VerifyingKeyResponse{VerifyingKey: hex.EncodeToString(buf.Bytes())})
+}
+
+// groupResponse describes the current state of a group
+func groupResponse(id string, s *semaphore.Semaphore) GroupResponse {
+	imt := s.GetGroup()
+	res := GroupResponse{ID: id, Size: imt.Size(), Domain: s.GetDomain()}
+	if imt.Size() != 0 {
+		res.Depth = imt.Depth()
+		res.Root = imt.Root().String()
+	}
+	return res
+}
+
+// memberResponse describes a member of a group and the current group root
+func memberResponse(s *semaphore.Semaphore, idc *big.Int) MemberResponse {
+	return MemberResponse{
+		Index:      s.IndexOf(idc),
+		Commitment: idc.String(),
+		Root:       s.GetGroup().Root().String(),
+	}
+}
+
+// decodeBody decodes the JSON body of a request, rejecting unknown fields
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_BODY_SIZE))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// writeJSON writes `v` as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// verificationStatus maps a verification error to its HTTP status code,
+// replayed nullifiers are reported as conflicts
+func verificationStatus(err error) int {
+	switch {
+	case errors.Is(err, semaphore.ErrNullifierUsed):
+		return http.StatusConflict
+	case errors.Is(err, semaphore.ErrInvalidMessage),
+		errors.Is(err, semaphore.ErrInvalidScope),
+		errors.Is(err, semaphore.ErrInvalidMerkleRoot),
+		errors.Is(err, semaphore.ErrInvalidProof),
+		errors.Is(err, semaphore.ErrStaleProof),
+		errors.Is(err, semaphore.ErrWrongDomain),
+		errors.Is(err, semaphore.ErrInvalidNullifierInput):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError writes `err` as a JSON error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/stretchr/testify/require"
+)
+
+// client sends JSON requests to the test server
+type client struct {
+	t   *testing.T
+	url string
+}
+
+// do sends a request with a JSON body and decodes the JSON response into `res`,
+// it returns the status code
+func (c *client) do(method, path string, body any, res any) int {
+	var r *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(c.t, err)
+		r = bytes.NewReader(data)
+	} else {
+		r = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.url+path, r)
+	require.NoError(c.t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	if res != nil {
+		require.NoError(c.t, json.NewDecoder(resp.Body).Decode(res))
+	}
+	return resp.StatusCode
+}
+
+// TestServer runs the group management and proof verification flow through the HTTP API
+func TestServer(t *testing.T) {
+	ccs, pk, vk, err := semaphore.SetupCircuit()
+	require.NoError(t, err)
+	ts := httptest.NewServer(NewServer(ccs, pk, vk).Handler())
+	defer ts.Close()
+	c := &client{t: t, url: ts.URL}
+
+	// Create a group
+	var group GroupResponse
+	require.Equal(t, http.StatusCreated, c.do("POST", "/groups", CreateGroupRequest{ID: "g"}, &group))
+	require.Equal(t, 0, group.Size)
+	require.Equal(t, http.StatusConflict, c.do("POST", "/groups", CreateGroupRequest{ID: "g"}, nil))
+	require.Equal(t, http.StatusBadRequest, c.do("POST", "/groups", CreateGroupRequest{}, nil))
+	require.Equal(t, http.StatusNotFound, c.do("GET", "/groups/g/root", nil, nil))
+	require.Equal(t, http.StatusNotFound, c.do("GET", "/groups/unknown", nil, nil))
+
+	// Add members
+	n := 5
+	secrets := []*big.Int{}
+	idcs := []*big.Int{}
+	for i := 0; i < n; i++ {
+		id, err := identity.New()
+		require.NoError(t, err)
+		secret, idc := id.Secret(), id.Commitment()
+		secrets = append(secrets, secret)
+		idcs = append(idcs, idc)
+
+		var member MemberResponse
+		require.Equal(t, http.StatusCreated, c.do("POST", "/groups/g/members", MemberRequest{Commitment: idc.String()}, &member))
+		require.Equal(t, i, member.Index)
+	}
+	require.Equal(t, http.StatusConflict, c.do("POST", "/groups/g/members", MemberRequest{Commitment: idcs[0].String()}, nil))
+	require.Equal(t, http.StatusBadRequest, c.do("POST", "/groups/g/members", MemberRequest{Commitment: "abc"}, nil))
+	require.Equal(t, http.StatusBadRequest, c.do("POST", "/groups/g/members", MemberRequest{Commitment: "0"}, nil))
+	require.Equal(t, http.StatusBadRequest, c.do("POST", "/groups/g/members", map[string]string{"unknown": "1"}, nil))
+
+	// Update and remove members
+	newID, err := identity.New()
+	require.NoError(t, err)
+	newSecret, newIdc := newID.Secret(), newID.Commitment()
+	require.Equal(t, http.StatusOK, c.do("PUT", "/groups/g/members/"+idcs[1].String(), MemberRequest{Commitment: newIdc.String()}, nil))
+	require.Equal(t, http.StatusNotFound, c.do("PUT", "/groups/g/members/"+idcs[1].String(), MemberRequest{Commitment: idcs[1].String()}, nil))
+	secrets[1], idcs[1] = newSecret, newIdc
+	require.Equal(t, http.StatusOK, c.do("DELETE", "/groups/g/members/"+idcs[4].String(), nil, nil))
+	require.Equal(t, http.StatusNotFound, c.do("DELETE", "/groups/g/members/"+idcs[4].String(), nil, nil))
+
+	// Fetch the root
+	require.Equal(t, http.StatusOK, c.do("GET", "/groups/g/root", nil, &group))
+	require.Equal(t, n, group.Size)
+
+	// Fetch the merkle proof of a random member
+	idx := rand.IntN(n - 1)
+	var mp MerkleProofResponse
+	require.Equal(t, http.StatusOK, c.do("GET", "/groups/g/members/"+strconv.Itoa(idx)+"/proof", nil, &mp))
+	require.Equal(t, group.Root, mp.Root)
+	require.Equal(t, idcs[idx].String(), mp.Leaf)
+	require.Equal(t, http.StatusNotFound, c.do("GET", "/groups/g/members/9/proof", nil, nil))
+	require.Equal(t, http.StatusBadRequest, c.do("GET", "/groups/g/members/x/proof", nil, nil))
+
+	// Generate the semaphore proof on the client side
+	merkleProof := leanIMT.MerkleProof{Path: mp.Path}
+	merkleProof.Node, _ = new(big.Int).SetString(mp.Leaf, 10)
+	merkleProof.Root, _ = new(big.Int).SetString(mp.Root, 10)
+	for _, sibling := range mp.Siblings {
+		v, _ := new(big.Int).SetString(sibling, 10)
+		merkleProof.Siblings = append(merkleProof.Siblings, v)
+	}
+	scope := big.NewInt(rand.Int64N(1000))
+	nullifier, err := semaphore.MimcHash([]*big.Int{scope, secrets[idx]})
+	require.NoError(t, err)
+	sProof := semaphore.SemaphoreProof{
+		MerkleRoot: merkleProof.Root,
+		Nullifier:  nullifier,
+		Message:    big.NewInt(rand.Int64N(1000)),
+		Scope:      scope,
+	}
+	proof, err := semaphore.GenerateSemaphoreProof(ccs, pk, secrets[idx], merkleProof, sProof)
+	require.NoError(t, err)
+	req, err := EncodeProofRequest(proof, sProof)
+	require.NoError(t, err)
+
+	// Invalid proofs are rejected
+	badReq := req
+	badReq.Message = "1001"
+	require.Equal(t, http.StatusUnprocessableEntity, c.do("POST", "/groups/g/proofs", badReq, nil))
+	badReq = req
+	badReq.Proof = "zz"
+	require.Equal(t, http.StatusBadRequest, c.do("POST", "/groups/g/proofs", badReq, nil))
+
+	// Verify the proof, then replay it
+	var res ProofResponse
+	require.Equal(t, http.StatusOK, c.do("POST", "/groups/g/proofs", req, &res))
+	require.True(t, res.Valid)
+	require.Equal(t, http.StatusConflict, c.do("POST", "/groups/g/proofs", req, nil))
+
+	// A group bound to a domain only accepts the proofs of its domain
+	domain := semaphore.Domain{AppID: "poll", ChainID: 1, Verifier: []byte{0xaa}}
+	members := []string{}
+	for _, idc := range idcs[:4] {
+		members = append(members, idc.String())
+	}
+	require.Equal(t, http.StatusBadRequest, c.do("POST", "/groups", map[string]any{"id": "d", "domain": map[string]any{"chainId": 1}}, nil))
+	require.Equal(t, http.StatusCreated, c.do("POST", "/groups", CreateGroupRequest{ID: "d", Members: members, Domain: &domain}, &group))
+	require.Equal(t, &domain, group.Domain)
+	require.Equal(t, http.StatusOK, c.do("GET", "/groups/d/members/"+strconv.Itoa(idx)+"/proof", nil, &mp))
+	merkleProof.Root, _ = new(big.Int).SetString(mp.Root, 10)
+	merkleProof.Path, merkleProof.Siblings = mp.Path, nil
+	for _, sibling := range mp.Siblings {
+		v, _ := new(big.Int).SetString(sibling, 10)
+		merkleProof.Siblings = append(merkleProof.Siblings, v)
+	}
+	boundScope, err := domain.Scope(scope)
+	require.NoError(t, err)
+	sProof.MerkleRoot = merkleProof.Root
+	sProof.Nullifier, err = semaphore.MimcHash([]*big.Int{boundScope, secrets[idx]})
+	require.NoError(t, err)
+	sProof.Domain = &domain
+	proof, err = semaphore.GenerateSemaphoreProof(ccs, pk, secrets[idx], merkleProof, sProof)
+	require.NoError(t, err)
+	req, err = EncodeProofRequest(proof, sProof)
+	require.NoError(t, err)
+	badReq = req
+	badReq.Domain = nil
+	require.Equal(t, http.StatusUnprocessableEntity, c.do("POST", "/groups/d/proofs", badReq, nil))
+	require.Equal(t, http.StatusUnprocessableEntity, c.do("POST", "/groups/g/proofs", req, nil))
+	require.Equal(t, http.StatusOK, c.do("POST", "/groups/d/proofs", req, &res))
+	require.True(t, res.Valid)
+
+	// Fetch the verifying key
+	var vkRes VerifyingKeyResponse
+	require.Equal(t, http.StatusOK, c.do("GET", "/verifying-key", nil, &vkRes))
+	require.NotEmpty(t, vkRes.VerifyingKey)
+}
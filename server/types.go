@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark-crypto/ecc"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// CreateGroupRequest is the body of POST /groups
+type CreateGroupRequest struct {
+	ID      string            `json:"id"`
+	Members []string          `json:"members,omitempty"`
+	Domain  *semaphore.Domain `json:"domain,omitempty"` // binds the group to a domain
+}
+
+// MemberRequest is the body of POST /groups/{id}/members and PUT /groups/{id}/members/{commitment}
+type MemberRequest struct {
+	Commitment string `json:"commitment"`
+}
+
+// GroupResponse describes the state of a group
+type GroupResponse struct {
+	ID     string            `json:"id"`
+	Size   int               `json:"size"`
+	Depth  int               `json:"depth"`
+	Root   string            `json:"root,omitempty"`
+	Domain *semaphore.Domain `json:"domain,omitempty"`
+}
+
+// MemberResponse describes a member of a group and the resulting group root
+type MemberResponse struct {
+	Index      int    `json:"index"`
+	Commitment string `json:"commitment"`
+	Root       string `json:"root"`
+}
+
+// MerkleProofResponse is the merkle proof of a group member
+type MerkleProofResponse struct {
+	Index    int      `json:"index"`
+	Leaf     string   `json:"leaf"`
+	Root     string   `json:"root"`
+	Path     []int    `json:"path"`
+	Siblings []string `json:"siblings"`
+}
+
+// ProofRequest is the body of POST /groups/{id}/proofs
+type ProofRequest struct {
+	MerkleRoot string            `json:"merkleRoot"`
+	Nullifier  string            `json:"nullifier"`
+	Message    string            `json:"message"`
+	Scope      string            `json:"scope"`
+	Proof      string            `json:"proof"`            // hex encoded groth16 proof
+	Domain     *semaphore.Domain `json:"domain,omitempty"` // set for the proofs bound to a domain
+}
+
+// ProofResponse is the result of a proof verification
+type ProofResponse struct {
+	Valid     bool   `json:"valid"`
+	Nullifier string `json:"nullifier"`
+}
+
+// VerifyingKeyResponse is the hex encoded groth16 verifying key
+type VerifyingKeyResponse struct {
+	VerifyingKey string `json:"verifyingKey"`
+}
+
+// ErrorResponse is returned with every non 2xx status code
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// parseField parses a decimal (or 0x prefixed hexadecimal) BN254 scalar field element
+func parseField(name, s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	if v.Sign() < 0 || v.Cmp(ecc.BN254.ScalarField()) >= 0 {
+		return nil, fmt.Errorf("%s is not a field element", name)
+	}
+	return v, nil
+}
+
+// parseCommitment parses an identity commitment, which can't be zero
+// since zero leaves are used for removed members
+func parseCommitment(s string) (*big.Int, error) {
+	v, err := parseField("commitment", s)
+	if err != nil {
+		return nil, err
+	}
+	if v.Sign() == 0 {
+		return nil, fmt.Errorf("commitment can't be zero")
+	}
+	return v, nil
+}
+
+// Decode validates the request and converts it into a semaphore proof and its groth16 proof
+func (r ProofRequest) Decode() (*groth16_bn254.Proof, semaphore.SemaphoreProof, error) {
+	var sProof semaphore.SemaphoreProof
+	var err error
+	if sProof.MerkleRoot, err = parseField("merkleRoot", r.MerkleRoot); err != nil {
+		return nil, sProof, err
+	}
+	if sProof.Nullifier, err = parseField("nullifier", r.Nullifier); err != nil {
+		return nil, sProof, err
+	}
+	if sProof.Message, err = parseField("message", r.Message); err != nil {
+		return nil, sProof, err
+	}
+	if sProof.Scope, err = parseField("scope", r.Scope); err != nil {
+		return nil, sProof, err
+	}
+	sProof.Domain = r.Domain
+
+	raw, err := hex.DecodeString(r.Proof)
+	if err != nil || len(raw) == 0 {
+		return nil, sProof, fmt.Errorf("invalid proof encoding")
+	}
+	proof := new(groth16_bn254.Proof)
+	if _, err := proof.ReadFrom(bytes.NewReader(raw)); err != nil {
+		return nil, sProof, fmt.Errorf("invalid proof: %v", err)
+	}
+	return proof, sProof, nil
+}
+
+// EncodeProofRequest converts a semaphore proof and its groth16 proof into a request body
+func EncodeProofRequest(proof *groth16_bn254.Proof, sProof semaphore.SemaphoreProof) (ProofRequest, error) {
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return ProofRequest{}, err
+	}
+	return ProofRequest{
+		MerkleRoot: sProof.MerkleRoot.String(),
+		Nullifier:  sProof.Nullifier.String(),
+		Message:    sProof.Message.String(),
+		Scope:      sProof.Scope.String(),
+		Proof:      hex.EncodeToString(buf.Bytes()),
+		Domain:     sProof.Domain,
+	}, nil
+}
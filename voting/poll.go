@@ -0,0 +1,199 @@
+package voting
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/leanIMT"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+)
+
+// Errors of the polls, use errors.Is to check them
+var (
+	ErrPollClosed       = errors.New("the poll is closed")
+	ErrAlreadyVoted     = errors.New("the voter has already voted")
+	ErrInvalidCandidate = errors.New("invalid candidate")
+)
+
+// Vote is a semaphore proof whose message is the index of a candidate
+// and whose scope is the scope of the poll
+type Vote struct {
+	Proof  *groth16_bn254.Proof
+	SProof semaphore.SemaphoreProof
+}
+
+// Result is the number of votes of a candidate
+type Result struct {
+	Candidate string
+	Votes     int
+}
+
+// Poll is an anonymous election among a group of voters, each voter votes once
+// with a semaphore proof of membership and the nullifier of the poll scope.
+// The voters must be registered before voting starts, since votes are only
+// verified against the current root of the group
+type Poll struct {
+	mu         sync.Mutex
+	id         string
+	candidates []string
+	scope      *big.Int
+	s          *semaphore.Semaphore
+	votes      []int
+	closed     bool
+}
+
+// PollScope returns the scope of the poll `id`
+func PollScope(id string) *big.Int {
+	return semaphore.HashToField([]byte(id))
+}
+
+// NewPoll creates a poll using an already setup Semaphore circuit
+func NewPoll(
+	id string,
+	candidates []string,
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	vk groth16.VerifyingKey,
+) (*Poll, error) {
+	if id == "" {
+		return nil, fmt.Errorf("missing poll id")
+	}
+	if len(candidates) < 2 {
+		return nil, fmt.Errorf("a poll needs at least 2 candidates")
+	}
+	p := &Poll{
+		id:         id,
+		candidates: append([]string{}, candidates...),
+		scope:      PollScope(id),
+		s:          semaphore.NewSemaphoreWithKeys(ccs, pk, vk),
+		votes:      make([]int, len(candidates)),
+	}
+	p.s.SetMessageValidator(func(message *big.Int) bool {
+		return message.IsInt64() && message.Int64() >= 0 && message.Int64() < int64(len(p.candidates))
+	})
+	p.s.SetScopeValidator(func(scope *big.Int) bool {
+		return scope.Cmp(p.scope) == 0
+	})
+	return p, nil
+}
+
+// ID returns the ID of the poll
+func (p *Poll) ID() string {
+	return p.id
+}
+
+// Scope returns the scope of the poll
+func (p *Poll) Scope() *big.Int {
+	return new(big.Int).Set(p.scope)
+}
+
+// Candidates returns the candidates of the poll
+func (p *Poll) Candidates() []string {
+	return append([]string{}, p.candidates...)
+}
+
+// AddVoters registers identity commitments as voters
+func (p *Poll) AddVoters(idcs []*big.Int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPollClosed
+	}
+	for _, idc := range idcs {
+		if p.s.IndexOf(idc) != -1 {
+			return fmt.Errorf("duplicated voter %v", idc)
+		}
+	}
+	return p.s.AddMembers(idcs)
+}
+
+// MerkleProof returns the merkle proof of a voter, used to generate its vote
+func (p *Poll) MerkleProof(idc *big.Int) (leanIMT.MerkleProof, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	idx := p.s.IndexOf(idc)
+	if idx == -1 {
+		return leanIMT.MerkleProof{}, semaphore.ErrMemberNotFound
+	}
+	return p.s.GenerateMerkleProof(idx)
+}
+
+// NewVote generates the vote of a voter for the candidate at index `candidate`
+func NewVote(
+	ccs constraint.ConstraintSystem,
+	pk groth16.ProvingKey,
+	secret *big.Int,
+	merkleProof leanIMT.MerkleProof,
+	scope *big.Int,
+	candidate int,
+) (*Vote, error) {
+	nullifier, err := semaphore.MimcHash([]*big.Int{scope, secret})
+	if err != nil {
+		return nil, err
+	}
+	sProof := semaphore.SemaphoreProof{
+		MerkleRoot: merkleProof.Root,
+		Nullifier:  nullifier,
+		Message:    big.NewInt(int64(candidate)),
+		Scope:      scope,
+	}
+	proof, err := semaphore.GenerateSemaphoreProof(ccs, pk, secret, merkleProof, sProof)
+	if err != nil {
+		return nil, err
+	}
+	return &Vote{Proof: proof, SProof: sProof}, nil
+}
+
+// Cast verifies a vote and counts it
+func (p *Poll) Cast(v *Vote) error {
+	if v == nil || v.Proof == nil {
+		return fmt.Errorf("missing vote")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPollClosed
+	}
+	if err := p.s.VerifyProof(v.Proof, v.SProof); err != nil {
+		switch {
+		case errors.Is(err, semaphore.ErrNullifierUsed):
+			return fmt.Errorf("%w: %w", ErrAlreadyVoted, err)
+		case errors.Is(err, semaphore.ErrInvalidMessage):
+			return fmt.Errorf("%w: %w", ErrInvalidCandidate, err)
+		}
+		return err
+	}
+	p.votes[v.SProof.Message.Int64()]++
+	return nil
+}
+
+// Tally returns the current number of votes of each candidate
+func (p *Poll) Tally() []Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	res := make([]Result, len(p.candidates))
+	for i := range p.candidates {
+		res[i] = Result{Candidate: p.candidates[i], Votes: p.votes[i]}
+	}
+	return res
+}
+
+// Close stops accepting votes and returns the final results
+func (p *Poll) Close() []Result {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return p.Tally()
+}
+
+// Closed returns true if the poll is closed
+func (p *Poll) Closed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
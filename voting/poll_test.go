@@ -0,0 +1,90 @@
+package voting
+
+import (
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+
+	"github.com/NguyenHiu/semaphore-implementation-in-go/identity"
+	"github.com/NguyenHiu/semaphore-implementation-in-go/semaphore"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPoll runs an election with dozens of voters
+func TestPoll(t *testing.T) {
+	ccs, pk, vk, err := semaphore.SetupCircuit()
+	require.NoError(t, err)
+
+	_, err = NewPoll("poll-1", []string{"alice"}, ccs, pk, vk)
+	require.Error(t, err)
+	p, err := NewPoll("poll-1", []string{"alice", "bob", "carol"}, ccs, pk, vk)
+	require.NoError(t, err)
+	require.Equal(t, semaphore.HashToField([]byte("poll-1")), p.Scope())
+
+	// Register the voters
+	n := 30
+	secrets := []*big.Int{}
+	idcs := []*big.Int{}
+	for i := 0; i < n; i++ {
+		id, err := identity.New()
+		require.NoError(t, err)
+		secrets = append(secrets, id.Secret())
+		idcs = append(idcs, id.Commitment())
+	}
+	require.NoError(t, p.AddVoters(idcs))
+	require.Error(t, p.AddVoters(idcs[:1]))
+
+	// Every voter votes once
+	expected := make([]int, 3)
+	votes := []*Vote{}
+	for i := 0; i < n; i++ {
+		candidate := mrand.IntN(3)
+		merkleProof, err := p.MerkleProof(idcs[i])
+		require.NoError(t, err)
+		vote, err := NewVote(ccs, pk, secrets[i], merkleProof, p.Scope(), candidate)
+		require.NoError(t, err)
+		require.NoError(t, p.Cast(vote))
+		expected[candidate]++
+		votes = append(votes, vote)
+	}
+
+	// Double votes are rejected, even for another candidate
+	require.ErrorIs(t, p.Cast(votes[0]), ErrAlreadyVoted)
+	merkleProof, err := p.MerkleProof(idcs[0])
+	require.NoError(t, err)
+	vote, err := NewVote(ccs, pk, secrets[0], merkleProof, p.Scope(), (int(votes[0].SProof.Message.Int64())+1)%3)
+	require.NoError(t, err)
+	require.ErrorIs(t, p.Cast(vote), ErrAlreadyVoted)
+
+	// The same vote with an aliased nullifier or candidate, equal modulo r, is rejected
+	for _, delta := range []*big.Int{fr.Modulus(), new(big.Int).Neg(fr.Modulus())} {
+		aliased := *votes[0]
+		aliased.SProof.Nullifier = new(big.Int).Add(votes[0].SProof.Nullifier, delta)
+		require.ErrorIs(t, p.Cast(&aliased), semaphore.ErrInvalidProof)
+		aliased = *votes[1]
+		aliased.SProof.Message = new(big.Int).Add(votes[1].SProof.Message, delta)
+		require.Error(t, p.Cast(&aliased))
+	}
+
+	// Votes for unknown candidates or other polls are rejected
+	vote, err = NewVote(ccs, pk, secrets[0], merkleProof, p.Scope(), 3)
+	require.NoError(t, err)
+	require.ErrorIs(t, p.Cast(vote), ErrInvalidCandidate)
+	vote, err = NewVote(ccs, pk, secrets[0], merkleProof, PollScope("poll-2"), 0)
+	require.NoError(t, err)
+	require.ErrorIs(t, p.Cast(vote), semaphore.ErrInvalidScope)
+
+	// Tally and close
+	results := p.Close()
+	require.True(t, p.Closed())
+	total := 0
+	for i, res := range results {
+		require.Equal(t, p.Candidates()[i], res.Candidate)
+		require.Equal(t, expected[i], res.Votes)
+		total += res.Votes
+	}
+	require.Equal(t, n, total)
+	require.ErrorIs(t, p.Cast(votes[1]), ErrPollClosed)
+	require.Equal(t, results, p.Tally())
+}